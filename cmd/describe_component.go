@@ -12,6 +12,13 @@ var describeComponentCmd = &cobra.Command{
 	Short:              "Execute 'describe component' command",
 	Long:               `This command shows configuration for a component in a stack: atmos describe component <component> -s <stack>`,
 	FParseErrWhitelist: struct{ UnknownFlags bool }{UnknownFlags: true},
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		stack, _ := cmd.Flags().GetString("stack")
+		return e.CompletionComponents(stack), cobra.ShellCompDirectiveNoFileComp
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		err := e.ExecuteDescribeComponent(cmd, args)
 		if err != nil {
@@ -23,11 +30,20 @@ var describeComponentCmd = &cobra.Command{
 func init() {
 	describeComponentCmd.DisableFlagParsing = false
 	describeComponentCmd.PersistentFlags().StringP("stack", "s", "", "atmos describe component <component> -s <stack>")
+	describeComponentCmd.PersistentFlags().Bool("include-source-metadata", false, "Add the '_metadata' section with the stack config files "+
+		"that contributed the component's values: atmos describe component <component> -s <stack> --include-source-metadata")
 
 	err := describeComponentCmd.MarkPersistentFlagRequired("stack")
 	if err != nil {
 		u.PrintErrorToStdErrorAndExit(err)
 	}
 
+	err = describeComponentCmd.RegisterFlagCompletionFunc("stack", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return e.CompletionStacks(), cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		u.PrintErrorToStdErrorAndExit(err)
+	}
+
 	describeCmd.AddCommand(describeComponentCmd)
 }
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	e "github.com/cloudposse/atmos/internal/exec"
+	u "github.com/cloudposse/atmos/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// describeStacksCmd describes configuration for all stacks
+var describeStacksCmd = &cobra.Command{
+	Use:                "stacks",
+	Short:              "Execute 'describe stacks' command",
+	Long:               `This command shows configuration for all stacks and components in the stacks: atmos describe stacks`,
+	FParseErrWhitelist: struct{ UnknownFlags bool }{UnknownFlags: true},
+	Run: func(cmd *cobra.Command, args []string) {
+		err := e.ExecuteDescribeStacks(cmd, args)
+		if err != nil {
+			u.PrintErrorToStdErrorAndExit(err)
+		}
+	},
+}
+
+func init() {
+	describeStacksCmd.DisableFlagParsing = false
+	describeStacksCmd.PersistentFlags().StringP("format", "f", "yaml", "'atmos describe stacks -f yaml' or 'atmos describe stacks -f json'")
+	describeStacksCmd.PersistentFlags().Bool("include-source-metadata", false, "Add the '_metadata' section with the stack config files "+
+		"that contributed the values to each component: atmos describe stacks --include-source-metadata")
+
+	describeCmd.AddCommand(describeStacksCmd)
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	e "github.com/cloudposse/atmos/internal/exec"
+	u "github.com/cloudposse/atmos/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// fmtCmd rewrites the CLI config files in the canonical format
+var fmtCmd = &cobra.Command{
+	Use:                "fmt",
+	Short:              "Execute 'fmt' command",
+	Long:               `This command rewrites the CLI config files in the canonical format (sorted keys, 2-space indentation): atmos fmt [<file>...]`,
+	FParseErrWhitelist: struct{ UnknownFlags bool }{UnknownFlags: true},
+	Run: func(cmd *cobra.Command, args []string) {
+		err := e.ExecuteFmt(cmd, args)
+		if err != nil {
+			u.PrintErrorToStdErrorAndExit(err)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(fmtCmd)
+}
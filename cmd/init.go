@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	e "github.com/cloudposse/atmos/internal/exec"
+	u "github.com/cloudposse/atmos/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// initCmd creates the directories for stacks, components and workflows, and a starter CLI config
+var initCmd = &cobra.Command{
+	Use:                "init",
+	Short:              "Execute 'init' command",
+	Long:               `This command creates the stacks, components and workflows directories and a starter CLI config (existing files are not changed): atmos init`,
+	FParseErrWhitelist: struct{ UnknownFlags bool }{UnknownFlags: true},
+	Run: func(cmd *cobra.Command, args []string) {
+		err := e.ExecuteInit(cmd, args)
+		if err != nil {
+			u.PrintErrorToStdErrorAndExit(err)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(initCmd)
+}
@@ -3,7 +3,10 @@ package cmd
 import (
 	e "github.com/cloudposse/atmos/internal/exec"
 	u "github.com/cloudposse/atmos/pkg/utils"
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"os"
 )
 
 // terraformCmd represents the base command for all terraform sub-commands
@@ -14,6 +17,11 @@ var terraformCmd = &cobra.Command{
 	FParseErrWhitelist: struct{ UnknownFlags bool }{UnknownFlags: true},
 	Run: func(cmd *cobra.Command, args []string) {
 		err := e.ExecuteTerraform(cmd, args)
+		// `terraform plan -detailed-exitcode` succeeded and the plan has changes, exit with the terraform exit code
+		if errors.Is(err, e.ErrChangesDetected) {
+			color.Yellow("\n%s\n", err)
+			os.Exit(2)
+		}
 		if err != nil {
 			u.PrintErrorToStdErrorAndExit(err)
 		}
@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// validateCmd validates stacks
+var validateCmd = &cobra.Command{
+	Use:                "validate",
+	Short:              "Execute 'validate' commands",
+	Long:               `This command validates stacks`,
+	FParseErrWhitelist: struct{ UnknownFlags bool }{UnknownFlags: true},
+}
+
+func init() {
+	RootCmd.AddCommand(validateCmd)
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	e "github.com/cloudposse/atmos/internal/exec"
+	u "github.com/cloudposse/atmos/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// validateStacksCmd finds the stack config files that don't produce valid stack names
+var validateStacksCmd = &cobra.Command{
+	Use:                "stacks",
+	Short:              "Execute 'validate stacks' command",
+	Long:               `This command finds the stack config files that don't produce valid stack names using the stack name pattern, and the stacks with the same names as the component directories: atmos validate stacks`,
+	FParseErrWhitelist: struct{ UnknownFlags bool }{UnknownFlags: true},
+	Run: func(cmd *cobra.Command, args []string) {
+		err := e.ExecuteValidateStacks(cmd, args)
+		if err != nil {
+			u.PrintErrorToStdErrorAndExit(err)
+		}
+	},
+}
+
+func init() {
+	validateCmd.AddCommand(validateStacksCmd)
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	c "github.com/cloudposse/atmos/pkg/config"
 	"github.com/spf13/cobra"
 )
 
@@ -17,5 +18,8 @@ var versionCmd = &cobra.Command{
 }
 
 func init() {
+	// `Version` is set at build time, the CLI config uses it to check `required_version`
+	c.AtmosVersion = Version
+
 	RootCmd.AddCommand(versionCmd)
 }
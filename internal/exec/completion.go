@@ -0,0 +1,88 @@
+package exec
+
+import (
+	"fmt"
+	c "github.com/cloudposse/atmos/pkg/config"
+	u "github.com/cloudposse/atmos/pkg/utils"
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+	"sort"
+	"sync"
+)
+
+var (
+	// completionStacksOnce guards the processing of the stack config files for shell completion
+	completionStacksOnce sync.Once
+	completionStacksMap  map[string]interface{}
+	completionStacksErr  error
+)
+
+// CompletionStacks returns a sorted list of all logical stack names for shell completion.
+// It does not print anything and never fails. If the stacks can't be processed, an empty slice is returned
+func CompletionStacks() []string {
+	stacksMap, err := processAllStacksForCompletion()
+	if err != nil {
+		return []string{}
+	}
+
+	return getStackNamesFromStacksMap(stacksMap)
+}
+
+// CompletionComponents returns a sorted list of all terraform and helmfile components in the provided logical stack for shell completion.
+// If the stack is empty, the components from all stacks are returned.
+// It does not print anything and never fails. If the stacks can't be processed, an empty slice is returned
+func CompletionComponents(stack string) []string {
+	stacksMap, err := processAllStacksForCompletion()
+	if err != nil {
+		return []string{}
+	}
+
+	stack = c.ResolveStackAlias(stack)
+
+	components := []string{}
+	forEachComponentInStacksMap(stacksMap, func(stackFileName string, componentType string, component string, componentSection map[string]interface{}) {
+		if len(stack) > 0 {
+			if stackName, ok := getComponentStackName(stackFileName, componentSection); !ok || stackName != stack {
+				return
+			}
+		}
+		components = append(components, component)
+	})
+
+	components = u.UniqueStrings(components)
+	sort.Strings(components)
+	return components
+}
+
+// processAllStacksForCompletion processes all stack config files at most once per process with all the output discarded
+func processAllStacksForCompletion() (map[string]interface{}, error) {
+	completionStacksOnce.Do(func() {
+		stdout := os.Stdout
+		colorOutput := color.Output
+
+		devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+		if err == nil {
+			os.Stdout = devNull
+		}
+		color.Output = io.Discard
+
+		defer func() {
+			os.Stdout = stdout
+			color.Output = colorOutput
+			if devNull != nil {
+				_ = devNull.Close()
+			}
+			// Shell completion must never crash the shell
+			if r := recover(); r != nil {
+				completionStacksMap = nil
+				completionStacksErr = errors.New(fmt.Sprintf("error processing the stacks for shell completion: %v", r))
+			}
+		}()
+
+		completionStacksMap, completionStacksErr = processAllStacks()
+	})
+
+	return completionStacksMap, completionStacksErr
+}
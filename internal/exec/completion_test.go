@@ -0,0 +1,61 @@
+package exec
+
+import (
+	g "github.com/cloudposse/atmos/pkg/globals"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// resetCompletionStacks clears the stacks processed for shell completion, so each test processes its own stacks
+func resetCompletionStacks(t *testing.T) {
+	reset := func() {
+		completionStacksOnce = sync.Once{}
+		completionStacksMap = nil
+		completionStacksErr = nil
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+func TestCompletionStacksAndComponents(t *testing.T) {
+	dir := setupTestStacks(t, testAtmosConfig, testStackConfigs)
+	resetCompletionStacks(t)
+
+	// Replace the console stdout to check that nothing is written to it, even in verbose mode
+	logVerbose := g.LogVerbose
+	g.LogVerbose = true
+	console, err := os.Create(filepath.Join(dir, "console.log"))
+	assert.Nil(t, err)
+	stdout := os.Stdout
+	os.Stdout = console
+	t.Cleanup(func() {
+		os.Stdout = stdout
+		g.LogVerbose = logVerbose
+		_ = console.Close()
+	})
+
+	assert.Equal(t, []string{"tenant1-dev", "tenant1-prod"}, CompletionStacks())
+	assert.Equal(t, []string{"echo-server", "eks", "vpc"}, CompletionComponents("tenant1-dev"))
+	assert.Equal(t, []string{"vpc"}, CompletionComponents("tenant1-prod"))
+	assert.Equal(t, []string{"echo-server", "eks", "vpc"}, CompletionComponents(""))
+	assert.Empty(t, CompletionComponents("tenant2-dev"))
+
+	// The stdout is restored and nothing is written to it
+	assert.Equal(t, console, os.Stdout)
+	consoleOutput, err := os.ReadFile(console.Name())
+	assert.Nil(t, err)
+	assert.Empty(t, string(consoleOutput))
+}
+
+func TestCompletionStacksWithInvalidStacks(t *testing.T) {
+	setupTestStacks(t, testAtmosConfig, map[string]string{
+		"orgs/tenant1/dev.yaml": "vars: [",
+	})
+	resetCompletionStacks(t)
+
+	assert.Equal(t, []string{}, CompletionStacks())
+	assert.Equal(t, []string{}, CompletionComponents("tenant1-dev"))
+}
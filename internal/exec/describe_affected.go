@@ -0,0 +1,86 @@
+package exec
+
+import (
+	"gopkg.in/yaml.v2"
+	"reflect"
+	"sort"
+)
+
+// DescribeAffected compares the provided base stacks (e.g. exported with `ExportStacks` from a base revision)
+// with the stacks in the current tree, and returns the sorted names of the logical stacks whose fully merged configurations differ.
+// Stacks that were added or removed in the current tree are also returned
+func DescribeAffected(baseStacks map[string]interface{}) ([]string, error) {
+	currentStacks, err := DescribeStacks()
+	if err != nil {
+		return nil, err
+	}
+
+	return findAffectedStacks(baseStacks, currentStacks)
+}
+
+// findAffectedStacks returns the sorted names of the stacks that differ between the base and current stacks.
+// The stacks are deep-compared (the order of the keys in maps does not matter, the order of the items in lists does)
+func findAffectedStacks(baseStacks map[string]interface{}, currentStacks map[string]interface{}) ([]string, error) {
+	stackNames := map[string]bool{}
+	for stackName := range baseStacks {
+		stackNames[stackName] = true
+	}
+	for stackName := range currentStacks {
+		stackNames[stackName] = true
+	}
+
+	var res []string
+
+	for stackName := range stackNames {
+		baseStack, inBase := baseStacks[stackName]
+		currentStack, inCurrent := currentStacks[stackName]
+
+		if !inBase || !inCurrent {
+			res = append(res, stackName)
+			continue
+		}
+
+		equal, err := stacksEqual(baseStack, currentStack)
+		if err != nil {
+			return nil, err
+		}
+		if !equal {
+			res = append(res, stackName)
+		}
+	}
+
+	sort.Strings(res)
+	return res, nil
+}
+
+// stacksEqual deep-compares two stack configs.
+// The configs are normalized by encoding them to YAML and decoding back, so the stacks read from an exported YAML file
+// (with `map[interface{}]interface{}` maps) can be compared with the stacks processed in memory (with `map[string]interface{}` maps)
+func stacksEqual(stack1 interface{}, stack2 interface{}) (bool, error) {
+	normalized1, err := normalizeStackConfig(stack1)
+	if err != nil {
+		return false, err
+	}
+
+	normalized2, err := normalizeStackConfig(stack2)
+	if err != nil {
+		return false, err
+	}
+
+	return reflect.DeepEqual(normalized1, normalized2), nil
+}
+
+// normalizeStackConfig encodes the stack config to YAML and decodes it back
+func normalizeStackConfig(stack interface{}) (interface{}, error) {
+	y, err := yaml.Marshal(stack)
+	if err != nil {
+		return nil, err
+	}
+
+	var res interface{}
+	err = yaml.Unmarshal(y, &res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
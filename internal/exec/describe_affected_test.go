@@ -0,0 +1,67 @@
+package exec
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+	"testing"
+)
+
+func TestFindAffectedStacks(t *testing.T) {
+	// The base stacks are read from an exported YAML stream
+	var baseStacks map[string]interface{}
+	err := yaml.Unmarshal([]byte(`
+tenant1-dev:
+  components:
+    terraform:
+      vpc:
+        vars:
+          stage: dev
+          cidr_block: 10.0.0.0/16
+          max_subnet_count: 3
+tenant1-prod:
+  components:
+    terraform:
+      vpc:
+        vars:
+          stage: prod
+tenant1-staging:
+  components:
+    terraform:
+      vpc:
+        vars:
+          stage: staging
+`), &baseStacks)
+	assert.Nil(t, err)
+
+	currentStacks := map[string]interface{}{
+		// The same config with the keys in a different order
+		"tenant1-dev": map[string]interface{}{
+			"components": map[string]interface{}{
+				"terraform": map[string]interface{}{
+					"vpc": map[string]interface{}{
+						"vars": map[string]interface{}{"max_subnet_count": 3, "cidr_block": "10.0.0.0/16", "stage": "dev"},
+					},
+				},
+			},
+		},
+		// Changed
+		"tenant1-prod": map[string]interface{}{
+			"components": map[string]interface{}{
+				"terraform": map[string]interface{}{
+					"vpc": map[string]interface{}{
+						"vars": map[string]interface{}{"stage": "prod", "enabled": false},
+					},
+				},
+			},
+		},
+		// Added
+		"tenant1-test": map[string]interface{}{
+			"components": map[string]interface{}{},
+		},
+	}
+
+	affected, err := findAffectedStacks(baseStacks, currentStacks)
+	assert.Nil(t, err)
+	// `tenant1-staging` was removed
+	assert.Equal(t, []string{"tenant1-prod", "tenant1-staging", "tenant1-test"}, affected)
+}
@@ -23,20 +23,16 @@ func ExecuteDescribeComponent(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	component := args[0]
+	includeSourceMetadata, err := flags.GetBool("include-source-metadata")
+	if err != nil {
+		return err
+	}
 
-	var configAndStacksInfo c.ConfigAndStacksInfo
-	configAndStacksInfo.ComponentFromArg = component
-	configAndStacksInfo.Stack = stack
+	component := args[0]
 
-	configAndStacksInfo.ComponentType = "terraform"
-	configAndStacksInfo, err = ProcessStacks(configAndStacksInfo)
+	componentSection, err := describeComponent(component, stack, includeSourceMetadata)
 	if err != nil {
-		configAndStacksInfo.ComponentType = "helmfile"
-		configAndStacksInfo, err = ProcessStacks(configAndStacksInfo)
-		if err != nil {
-			return err
-		}
+		return err
 	}
 
 	if g.LogVerbose {
@@ -44,10 +40,37 @@ func ExecuteDescribeComponent(cmd *cobra.Command, args []string) error {
 		color.Cyan("Component config:\n\n")
 	}
 
-	err = u.PrintAsYAML(configAndStacksInfo.ComponentSection)
+	err = u.PrintAsYAML(componentSection)
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// DescribeComponent accepts a component and a stack name and returns the fully merged component configuration in the stack
+// (vars, settings, env, backend, metadata, etc.)
+func DescribeComponent(component string, stack string) (map[string]interface{}, error) {
+	return describeComponent(component, stack, false)
+}
+
+// describeComponent returns the fully merged component configuration in the stack.
+// If `includeSourceMetadata` is `true`, the result has the `_metadata` section with the stack config files that contributed the component's values
+func describeComponent(component string, stack string, includeSourceMetadata bool) (map[string]interface{}, error) {
+	var configAndStacksInfo c.ConfigAndStacksInfo
+	configAndStacksInfo.ComponentFromArg = component
+	configAndStacksInfo.Stack = stack
+	configAndStacksInfo.IncludeSourceMetadata = includeSourceMetadata
+
+	configAndStacksInfo.ComponentType = "terraform"
+	configAndStacksInfo, err := ProcessStacks(configAndStacksInfo)
+	if err != nil {
+		configAndStacksInfo.ComponentType = "helmfile"
+		configAndStacksInfo, err = ProcessStacks(configAndStacksInfo)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return configAndStacksInfo.ComponentSection, nil
+}
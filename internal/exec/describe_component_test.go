@@ -0,0 +1,89 @@
+package exec
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDescribeComponent(t *testing.T) {
+	setupTestStacks(t, testAtmosConfig, map[string]string{
+		"catalog/vpc.yaml": `
+components:
+  terraform:
+    vpc-defaults:
+      vars:
+        cidr_block: 10.0.0.0/16
+        enabled: false
+      env:
+        TF_LOG: INFO
+      settings:
+        version: 1
+      backend:
+        s3:
+          key: vpc
+`,
+		"orgs/tenant1/dev.yaml": `
+import:
+  - catalog/vpc
+vars:
+  tenant: tenant1
+  stage: dev
+terraform:
+  vars:
+    region: us-east-2
+  env:
+    TF_IN_AUTOMATION: "true"
+  backend_type: s3
+  backend:
+    s3:
+      bucket: tfstate
+components:
+  terraform:
+    vpc:
+      component: vpc-defaults
+      vars:
+        enabled: true
+      settings:
+        spacelift:
+          workspace_enabled: true
+  helmfile:
+    echo-server:
+      vars:
+        installed: true
+`,
+	})
+
+	componentSection, err := DescribeComponent("vpc", "tenant1-dev")
+	assert.Nil(t, err)
+
+	// The vars are merged from the global, terraform, base component and component sections
+	vars := componentSection["vars"].(map[interface{}]interface{})
+	assert.Equal(t, "tenant1", vars["tenant"])
+	assert.Equal(t, "dev", vars["stage"])
+	assert.Equal(t, "us-east-2", vars["region"])
+	assert.Equal(t, "10.0.0.0/16", vars["cidr_block"])
+	assert.Equal(t, true, vars["enabled"])
+
+	// The backend is merged from the terraform and base component sections
+	assert.Equal(t, "s3", componentSection["backend_type"])
+	backend := componentSection["backend"].(map[interface{}]interface{})
+	assert.Equal(t, "tfstate", backend["bucket"])
+	assert.Equal(t, "vpc", backend["key"])
+
+	// The env and settings are inherited from the base component
+	env := componentSection["env"].(map[interface{}]interface{})
+	assert.Equal(t, "INFO", env["TF_LOG"])
+	assert.Equal(t, "true", env["TF_IN_AUTOMATION"])
+	settings := componentSection["settings"].(map[interface{}]interface{})
+	assert.Equal(t, 1, settings["version"])
+	assert.Equal(t, map[interface{}]interface{}{"workspace_enabled": true}, settings["spacelift"])
+
+	assert.Equal(t, "vpc-defaults", componentSection["component"])
+
+	// The helmfile components are described if there is no terraform component with the name
+	componentSection, err = DescribeComponent("echo-server", "tenant1-dev")
+	assert.Nil(t, err)
+	vars = componentSection["vars"].(map[interface{}]interface{})
+	assert.Equal(t, true, vars["installed"])
+	assert.Equal(t, "tenant1", vars["tenant"])
+}
@@ -21,6 +21,11 @@ func ExecuteDescribeConfig(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	err = c.ApplyEnvVars()
+	if err != nil {
+		return err
+	}
+
 	if format == "json" {
 		err = u.PrintAsJSON(c.Config)
 	} else if format == "yaml" {
@@ -0,0 +1,140 @@
+package exec
+
+import (
+	"fmt"
+	c "github.com/cloudposse/atmos/pkg/config"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ExecuteDescribeStacks executes `describe stacks` command
+func ExecuteDescribeStacks(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+
+	format, err := flags.GetString("format")
+	if err != nil {
+		return err
+	}
+
+	if format != "json" && format != "yaml" {
+		return errors.New("invalid flag '--format'. Accepted values are 'json' or 'yaml'")
+	}
+
+	includeSourceMetadata, err := flags.GetBool("include-source-metadata")
+	if err != nil {
+		return err
+	}
+
+	stacks, err := describeStacks(includeSourceMetadata)
+	if err != nil {
+		return err
+	}
+
+	// Encode the stacks directly to stdout instead of building the whole output in memory
+	if format == "json" {
+		encoder := jsoniter.ConfigCompatibleWithStandardLibrary.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stacks)
+	}
+
+	encoder := yaml.NewEncoder(os.Stdout)
+	err = encoder.Encode(stacks)
+	if err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// DescribeStacks processes all stack config files (including imports and inheritance) and returns a map of all logical stacks
+// with the fully merged configurations of the terraform and helmfile components in each stack
+func DescribeStacks() (map[string]interface{}, error) {
+	return describeStacks(false)
+}
+
+// describeStacks returns a map of all logical stacks.
+// The components from the stack config files that resolve to the same logical stack are merged into the stack,
+// and it's an error if the same component is defined in more than one of these files.
+// If `includeSourceMetadata` is `true`, each component gets the `_metadata` section with the stack config files that contributed its values
+func describeStacks(includeSourceMetadata bool) (map[string]interface{}, error) {
+	stacksMap, err := processAllStacksWithSourceMetadata(includeSourceMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[string]interface{}{}
+	componentStackFiles := map[string]string{}
+	var collisions []string
+
+	forEachComponentInStacksMap(stacksMap, func(stackFileName string, componentType string, component string, componentSection map[string]interface{}) {
+		stackName, ok := getComponentStackName(stackFileName, componentSection)
+		if !ok {
+			return
+		}
+
+		key := fmt.Sprintf("%s/%s/%s", stackName, componentType, component)
+		if previousStackFileName, ok := componentStackFiles[key]; ok {
+			stackFileNames := []string{previousStackFileName, stackFileName}
+			sort.Strings(stackFileNames)
+			collisions = append(collisions, fmt.Sprintf("the %s component '%s' in the stack '%s' is defined in the stack config files '%s' and '%s'",
+				componentType, component, stackName, stackFileNames[0], stackFileNames[1]))
+			return
+		}
+		componentStackFiles[key] = stackFileName
+
+		if _, ok := res[stackName]; !ok {
+			res[stackName] = map[string]interface{}{
+				"components": map[string]interface{}{},
+			}
+		}
+
+		componentsSection := res[stackName].(map[string]interface{})["components"].(map[string]interface{})
+		if _, ok := componentsSection[componentType]; !ok {
+			componentsSection[componentType] = map[string]interface{}{}
+		}
+
+		componentsSection[componentType].(map[string]interface{})[component] = componentSection
+	})
+
+	if len(collisions) > 0 {
+		sort.Strings(collisions)
+		return nil, errors.New(fmt.Sprintf("%s.\nCheck the stack name pattern '%s'", strings.Join(collisions, "\n"), c.Config.Stacks.NamePattern))
+	}
+
+	return res, nil
+}
+
+// ExportStacks writes the fully merged configurations of all logical stacks to the provided writer as a multi-document YAML stream.
+// Each document (separated by `---`) contains one stack with the logical stack name as the top-level key.
+// The stacks are sorted by name, so the output can be reviewed and diffed
+func ExportStacks(w io.Writer) error {
+	stacks, err := DescribeStacks()
+	if err != nil {
+		return err
+	}
+
+	return writeStacksAsYAMLStream(w, stacks)
+}
+
+// writeStacksAsYAMLStream encodes each stack as a separate YAML document to the provided writer
+func writeStacksAsYAMLStream(w io.Writer, stacks map[string]interface{}) error {
+	var stackNames []string
+	for stackName := range stacks {
+		stackNames = append(stackNames, stackName)
+	}
+	sort.Strings(stackNames)
+
+	encoder := yaml.NewEncoder(w)
+	for _, stackName := range stackNames {
+		err := encoder.Encode(map[string]interface{}{stackName: stacks[stackName]})
+		if err != nil {
+			return err
+		}
+	}
+	return encoder.Close()
+}
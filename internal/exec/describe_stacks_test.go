@@ -0,0 +1,114 @@
+package exec
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWriteStacksAsYAMLStream(t *testing.T) {
+	stacks := map[string]interface{}{
+		"tenant1-prod": map[string]interface{}{
+			"components": map[string]interface{}{
+				"terraform": map[string]interface{}{
+					"vpc": map[string]interface{}{"vars": map[string]interface{}{"stage": "prod"}},
+				},
+			},
+		},
+		"tenant1-dev": map[string]interface{}{
+			"components": map[string]interface{}{
+				"terraform": map[string]interface{}{
+					"vpc": map[string]interface{}{"vars": map[string]interface{}{"stage": "dev"}},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	err := writeStacksAsYAMLStream(&buf, stacks)
+	assert.Nil(t, err)
+	assert.Equal(t, `tenant1-dev:
+  components:
+    terraform:
+      vpc:
+        vars:
+          stage: dev
+---
+tenant1-prod:
+  components:
+    terraform:
+      vpc:
+        vars:
+          stage: prod
+`, buf.String())
+}
+
+func TestDescribeStacksWithSameStackName(t *testing.T) {
+	setupTestStacks(t, `
+stacks:
+  base_path: "stacks"
+  included_paths:
+    - "**/*"
+  name_pattern: "{stage}"
+`, map[string]string{
+		"network/dev.yaml": `
+vars:
+  stage: dev
+components:
+  terraform:
+    vpc:
+      vars: {}
+`,
+		"apps/dev.yaml": `
+vars:
+  stage: dev
+components:
+  helmfile:
+    echo-server:
+      vars: {}
+`,
+	})
+
+	// The different components from the stack config files with the same logical stack name are merged into the stack
+	stacks, err := DescribeStacks()
+	assert.Nil(t, err)
+	assert.Len(t, stacks, 1)
+	components := stacks["dev"].(map[string]interface{})["components"].(map[string]interface{})
+	assert.Contains(t, components["terraform"], "vpc")
+	assert.Contains(t, components["helmfile"], "echo-server")
+}
+
+func TestDescribeStacksWithSameComponentInSameStackName(t *testing.T) {
+	setupTestStacks(t, `
+stacks:
+  base_path: "stacks"
+  included_paths:
+    - "**/*"
+  name_pattern: "{stage}"
+`, map[string]string{
+		"tenant1/dev.yaml": `
+vars:
+  stage: dev
+components:
+  terraform:
+    vpc:
+      vars: {}
+`,
+		"tenant2/dev.yaml": `
+vars:
+  stage: dev
+components:
+  terraform:
+    vpc:
+      vars: {}
+`,
+	})
+
+	_, err := DescribeStacks()
+	assert.NotNil(t, err)
+	assert.Equal(t, "the terraform component 'vpc' in the stack 'dev' is defined in the stack config files 'tenant1/dev' and 'tenant2/dev'.\n"+
+		"Check the stack name pattern '{stage}'", err.Error())
+
+	var buf bytes.Buffer
+	assert.NotNil(t, ExportStacks(&buf))
+}
@@ -0,0 +1,27 @@
+package exec
+
+import (
+	"fmt"
+	c "github.com/cloudposse/atmos/pkg/config"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// ExecuteFmt executes `fmt` command
+func ExecuteFmt(cmd *cobra.Command, args []string) error {
+	// The CLI config file in the current directory is formatted if no files are provided
+	configFiles := args
+	if len(configFiles) == 0 {
+		configFiles = []string{c.GetConfigFileName()}
+	}
+
+	for _, configFile := range configFiles {
+		err := c.FormatConfigFile(configFile)
+		if err != nil {
+			return err
+		}
+		color.Cyan(fmt.Sprintf("Formatted '%s'", configFile))
+	}
+
+	return nil
+}
@@ -10,9 +10,12 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"os"
-	"path"
+	"path/filepath"
 )
 
+// helmfileProvisionSubCommands are the helmfile subcommands that can't be executed for abstract components (`metadata.type: abstract`)
+var helmfileProvisionSubCommands = []string{"sync", "apply", "deploy"}
+
 // ExecuteHelmfile executes helmfile commands
 func ExecuteHelmfile(cmd *cobra.Command, args []string) error {
 	info, err := processArgsConfigAndStacks("helmfile", cmd, args)
@@ -33,20 +36,39 @@ func ExecuteHelmfile(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// Check if the component is allowed to be executed (`components.allowlist` and `components.denylist` config)
+	err = checkComponentAllowed(info.ComponentFromArg)
+	if err != nil {
+		return err
+	}
+
+	// In strict mode, check that the binary to execute can be found
+	if c.Config.Settings.StrictMode {
+		err = checkCommandExists(info.Command)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Check if the component exists as a helmfile component
-	componentPath := path.Join(c.ProcessedConfig.HelmfileDirAbsolutePath, info.ComponentFolderPrefix, info.FinalComponent)
-	componentPathExists, err := utils.IsDirectory(componentPath)
-	if err != nil || !componentPathExists {
-		return errors.New(fmt.Sprintf("Component '%s' does not exist in '%s'",
-			info.FinalComponent,
-			path.Join(c.ProcessedConfig.HelmfileDirAbsolutePath, info.ComponentFolderPrefix),
-		))
+	componentPath, err := HelmfileWorkingDir(filepath.Join(info.ComponentFolderPrefix, info.FinalComponent))
+	if err != nil {
+		return err
 	}
 
 	// Check if the component is allowed to be provisioned (`metadata.type` attribute)
-	if (info.SubCommand == "sync" || info.SubCommand == "apply" || info.SubCommand == "deploy") && info.ComponentIsAbstract {
-		return errors.New(fmt.Sprintf("Abstract component '%s' cannot be provisioned since it's explicitly prohibited from being deployed "+
-			"by 'metadata.type: abstract' attribute", path.Join(info.ComponentFolderPrefix, info.Component)))
+	err = checkComponentNotAbstract(info, helmfileProvisionSubCommands)
+	if err != nil {
+		return err
+	}
+
+	// Prevent concurrent atmos runs against the same component and stack
+	if !info.DryRun {
+		unlock, err := lockComponent(componentPath, info.ComponentFromArg, info.Stack)
+		if err != nil {
+			return err
+		}
+		defer unlock()
 	}
 
 	// Print component variables
@@ -58,13 +80,26 @@ func ExecuteHelmfile(cmd *cobra.Command, args []string) error {
 
 	// Write variables to a file
 	varFile := constructHelmfileComponentVarfileName(info)
-	varFilePath := constructHelmfileComponentVarfilePath(info)
+	varFilePath := constructHelmfileComponentVarfilePath(componentPath, info)
+
+	// Files generated by atmos in this run. If `--clean-generated` flag is specified, they are deleted after the command completes
+	var generatedFiles []string
+	if info.CleanGenerated {
+		defer func() {
+			removeGeneratedFiles(generatedFiles)
+		}()
+	}
 
 	color.Cyan("Writing the variables to file:")
 	fmt.Println(varFilePath)
-	err = utils.WriteToFileAsYAML(varFilePath, info.ComponentVarsSection, 0644)
-	if err != nil {
-		return err
+	if !info.DryRun {
+		if info.CleanGenerated {
+			generatedFiles = trackGeneratedFile(generatedFiles, varFilePath)
+		}
+		err = utils.WriteToFileAsYAML(varFilePath, info.ComponentVarsSection, 0644)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Handle `helmfile deploy` custom command
@@ -83,7 +118,7 @@ func ExecuteHelmfile(cmd *cobra.Command, args []string) error {
 	clusterName := c.ReplaceContextTokens(context, c.Config.Components.Helmfile.ClusterNamePattern)
 	color.Cyan(fmt.Sprintf("Downloading kubeconfig from the cluster '%s' and saving it to %s\n\n", clusterName, kubeconfigPath))
 
-	err = execCommand("aws",
+	_, err = execCommand("aws",
 		[]string{
 			"--profile",
 			helmAwsProfile,
@@ -95,6 +130,7 @@ func ExecuteHelmfile(cmd *cobra.Command, args []string) error {
 		},
 		componentPath,
 		nil,
+		getExecOptions(info),
 	)
 	if err != nil {
 		return err
@@ -122,11 +158,10 @@ func ExecuteHelmfile(cmd *cobra.Command, args []string) error {
 		fmt.Println("Stack: " + info.StackFromArg)
 	} else {
 		fmt.Println("Stack: " + info.StackFromArg)
-		fmt.Println("Stack path: " + path.Join(c.Config.BasePath, c.Config.Stacks.BasePath, info.Stack))
+		fmt.Println("Stack path: " + filepath.Join(c.Config.BasePath, c.Config.Stacks.BasePath, info.Stack))
 	}
 
-	workingDir := constructHelmfileComponentWorkingDir(info)
-	fmt.Println(fmt.Sprintf("Working dir: %s\n\n", workingDir))
+	fmt.Println(fmt.Sprintf("Working dir: %s\n\n", componentPath))
 
 	// Prepare arguments and flags
 	allArgsAndFlags := []string{"--state-values-file", varFile}
@@ -153,15 +188,17 @@ func ExecuteHelmfile(cmd *cobra.Command, args []string) error {
 		fmt.Println(v)
 	}
 
-	err = execCommand(info.Command, allArgsAndFlags, componentPath, envVars)
+	_, err = execCommand(info.Command, allArgsAndFlags, componentPath, envVars, getExecOptions(info))
 	if err != nil {
 		return err
 	}
 
 	// Cleanup
-	err = os.Remove(varFilePath)
-	if err != nil {
-		color.Yellow("Error deleting the helmfile varfile: %s\n", err)
+	if !info.DryRun {
+		err = os.Remove(varFilePath)
+		if err != nil {
+			color.Yellow("Error deleting the helmfile varfile: %s\n", err)
+		}
 	}
 
 	return nil
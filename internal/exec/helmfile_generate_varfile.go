@@ -7,6 +7,7 @@ import (
 	"github.com/cloudposse/atmos/pkg/utils"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"path/filepath"
 )
 
 // ExecuteHelmfileGenerateVarfile executes `helmfile generate varfile` command
@@ -45,7 +46,11 @@ func ExecuteHelmfileGenerateVarfile(cmd *cobra.Command, args []string) error {
 	if len(varFileNameFromArg) > 0 {
 		varFilePath = varFileNameFromArg
 	} else {
-		varFilePath = constructHelmfileComponentVarfilePath(info)
+		workingDir, err := HelmfileWorkingDir(filepath.Join(info.ComponentFolderPrefix, info.FinalComponent))
+		if err != nil {
+			return err
+		}
+		varFilePath = constructHelmfileComponentVarfilePath(workingDir, info)
 	}
 
 	// Print the component variables
@@ -17,6 +17,10 @@ func processHelp(componentType string, command string) error {
 			fmt.Println()
 			color.Cyan("Additions and differences from native terraform:")
 			fmt.Println(" - before executing other 'terraform' commands, 'atmos' calls 'terraform init'")
+			fmt.Println(" - 'terraform init' is retried with exponential backoff if it fails. The number of retries (default 0) and the initial delay " +
+				"between the retries (default '5s') are configured with 'ATMOS_INIT_RETRIES' and 'ATMOS_INIT_RETRY_DELAY' ENV vars")
+			fmt.Println(" - 'atmos terraform plan -detailed-exitcode' exits with the code 2 if the plan has changes (as 'terraform plan' does). " +
+				"Only the exit code 1 is treated as a failure")
 			fmt.Println(" - 'atmos terraform deploy' command executes 'terraform plan' and then 'terraform apply'")
 			fmt.Println(" - 'atmos terraform deploy' command supports '--deploy-run-init=true/false' flag to enable/disable running 'terraform init' " +
 				"before executing the command")
@@ -32,6 +36,38 @@ func processHelp(componentType string, command string) error {
 			fmt.Println(" - 'atmos terraform generate backend' command generates the backend file for the component in the stack")
 			fmt.Println(" - 'atmos terraform generate varfile' command generates a varfile for the component in the stack")
 			fmt.Println(" - 'atmos terraform shell' command configures an environment for the component in the stack and starts a new shell allowing executing all native terraform commands")
+			fmt.Println(" - 'atmos terraform' commands support '--dry-run' flag (or 'ATMOS_DRY_RUN=true' ENV var). If the flag is specified, the commands " +
+				"print the terraform commands and the working directories, but do not execute them")
+			fmt.Println(" - 'atmos terraform' commands support '--config <file>' flag to merge an additional CLI config file on top of the discovered " +
+				"CLI config files. The flag can be repeated, the files are merged in the order given")
+			fmt.Println(" - 'atmos terraform' commands support '--clean-generated' flag. If the flag is specified, the varfiles and backend files generated by 'atmos' " +
+				"in the current run are deleted after the command completes (whether it succeeds or fails). Existing files are never deleted")
+			fmt.Println(" - 'atmos terraform' commands support '--no-schema-validation' flag to disable the validation of the CLI config " +
+				"against the JSON Schema")
+			fmt.Println(" - 'atmos terraform' commands support '--stack-name-pattern' flag to override the stack name pattern " +
+				"from the 'stacks.name_pattern' config and 'ATMOS_STACKS_NAME_PATTERN' ENV var")
+			fmt.Println(" - the executed terraform commands are killed (with all their subprocesses) if they don't complete in the time specified in " +
+				"'ATMOS_COMMAND_TIMEOUT' ENV var (e.g. '30m'). The timeout is intended for non-interactive runs (e.g. in CI)")
+			fmt.Println(" - 'atmos terraform' commands lock the component in the stack (with an OS advisory lock on a file in the temp dir), " +
+				"and fail if another atmos process is operating on the same component and stack. " +
+				"Use 'ATMOS_LOCK_TIMEOUT' ENV var (e.g. '5m') to wait for the lock instead of failing immediately")
+			fmt.Println(" - 'atmos terraform' commands don't allow specifying these flags together: '--from-plan' and '--deploy-run-init', " +
+				"'--dry-run' and '--from-plan', '--from-plan' and '--var'")
+			fmt.Println(" - if 'components.terraform.discover_var_files' is 'true' (or 'ATMOS_COMPONENTS_TERRAFORM_DISCOVER_VAR_FILES=true' ENV var), " +
+				"the '<stack>.tfvars' and '<stack>.tfvars.json' files in the component directory are passed to terraform with '-var-file' " +
+				"after the varfile generated by atmos")
+			fmt.Println(" - 'atmos terraform' commands support '--redirect-stderr <file>' flag. If the flag is specified, the stderr of the executed " +
+				"terraform commands is appended to the file in addition to being shown on the console")
+			fmt.Println(" - 'atmos terraform' commands support '--redirect-stdout <file>' flag. If the flag is specified, the stdout of the executed " +
+				"terraform commands is appended to the file in addition to being shown on the console")
+			fmt.Println(" - 'atmos terraform' commands support '--vars-file <file>' flag to read the variables of the component from a YAML or JSON file. " +
+				"The variables override the variables from the stack config. If 'stacks.require_stacks' is 'false' and no stack config files are found, " +
+				"the variables are taken only from the file")
+			fmt.Println(" - 'atmos terraform' commands support '--logs-level <level>' flag to set the logs level " +
+				"('trace', 'debug', 'info', 'warn' or 'error'). It can also be set with 'ATMOS_LOGS_LEVEL' ENV var")
+			fmt.Println(" - 'atmos terraform' commands support '--var key=value' flag to override a variable of the component in the stack. " +
+				"The flag can be repeated. The values are parsed as JSON (e.g. '--var count=3', '--var enabled=false'), " +
+				"or used as strings if they are not valid JSON")
 		}
 
 		if componentType == "helmfile" {
@@ -42,9 +78,36 @@ func processHelp(componentType string, command string) error {
 				"Usage: atmos helmfile <command> <component> -s <stack> [command options] [arguments...] --global-options=\"--no-color --namespace=test\"")
 			fmt.Println(" - before executing the 'helmfile' commands, 'atmos' calls 'aws eks update-kubeconfig' to read kubeconfig from the EKS cluster " +
 				"and use it to authenticate with the cluster")
+			fmt.Println(" - 'atmos helmfile' commands support '--dry-run' flag (or 'ATMOS_DRY_RUN=true' ENV var). If the flag is specified, the commands " +
+				"print the helmfile commands and the working directories, but do not execute them")
+			fmt.Println(" - 'atmos helmfile' commands support '--config <file>' flag to merge an additional CLI config file on top of the discovered " +
+				"CLI config files. The flag can be repeated, the files are merged in the order given")
+			fmt.Println(" - 'atmos helmfile' commands support '--clean-generated' flag. If the flag is specified, the varfiles generated by 'atmos' " +
+				"in the current run are deleted after the command completes (whether it succeeds or fails). Existing files are never deleted")
+			fmt.Println(" - 'atmos helmfile' commands support '--no-schema-validation' flag to disable the validation of the CLI config " +
+				"against the JSON Schema")
+			fmt.Println(" - 'atmos helmfile' commands support '--stack-name-pattern' flag to override the stack name pattern " +
+				"from the 'stacks.name_pattern' config and 'ATMOS_STACKS_NAME_PATTERN' ENV var")
+			fmt.Println(" - the executed helmfile commands are killed (with all their subprocesses) if they don't complete in the time specified in " +
+				"'ATMOS_COMMAND_TIMEOUT' ENV var (e.g. '30m'). The timeout is intended for non-interactive runs (e.g. in CI)")
+			fmt.Println(" - 'atmos helmfile' commands lock the component in the stack (with an OS advisory lock on a file in the temp dir), " +
+				"and fail if another atmos process is operating on the same component and stack. " +
+				"Use 'ATMOS_LOCK_TIMEOUT' ENV var (e.g. '5m') to wait for the lock instead of failing immediately")
+			fmt.Println(" - 'atmos helmfile' commands support '--redirect-stderr <file>' flag. If the flag is specified, the stderr of the executed " +
+				"helmfile commands is appended to the file in addition to being shown on the console")
+			fmt.Println(" - 'atmos helmfile' commands support '--redirect-stdout <file>' flag. If the flag is specified, the stdout of the executed " +
+				"helmfile commands is appended to the file in addition to being shown on the console")
+			fmt.Println(" - 'atmos helmfile' commands support '--vars-file <file>' flag to read the variables of the component from a YAML or JSON file. " +
+				"The variables override the variables from the stack config. If 'stacks.require_stacks' is 'false' and no stack config files are found, " +
+				"the variables are taken only from the file")
+			fmt.Println(" - 'atmos helmfile' commands support '--logs-level <level>' flag to set the logs level " +
+				"('trace', 'debug', 'info', 'warn' or 'error'). It can also be set with 'ATMOS_LOGS_LEVEL' ENV var")
+			fmt.Println(" - 'atmos helmfile' commands support '--var key=value' flag to override a variable of the component in the stack. " +
+				"The flag can be repeated. The values are parsed as JSON (e.g. '--var count=3', '--var enabled=false'), " +
+				"or used as strings if they are not valid JSON")
 		}
 
-		err := execCommand(componentType, []string{"--help"}, "", nil)
+		_, err := execCommand(componentType, []string{"--help"}, "", nil, ExecOptions{})
 		if err != nil {
 			return err
 		}
@@ -54,7 +117,7 @@ func processHelp(componentType string, command string) error {
 		color.Cyan(fmt.Sprintf("atmos %s %s <component> -s <stack> [options]", componentType, command))
 		color.Cyan(fmt.Sprintf("atmos %s %s <component> --stack <stack> [options]", componentType, command))
 
-		err := execCommand(componentType, []string{command, "--help"}, "", nil)
+		_, err := execCommand(componentType, []string{command, "--help"}, "", nil, ExecOptions{})
 		if err != nil {
 			return err
 		}
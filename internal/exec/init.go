@@ -0,0 +1,11 @@
+package exec
+
+import (
+	c "github.com/cloudposse/atmos/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// ExecuteInit executes `init` command
+func ExecuteInit(cmd *cobra.Command, args []string) error {
+	return c.Bootstrap()
+}
@@ -0,0 +1,88 @@
+package exec
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var (
+	// lockRetryInterval is the interval between the attempts to acquire the lock when `ATMOS_LOCK_TIMEOUT` is set
+	lockRetryInterval = 500 * time.Millisecond
+)
+
+// getLockTimeout returns the time to wait for the component lock specified in `ATMOS_LOCK_TIMEOUT` ENV var.
+// If the ENV var is not set, atmos does not wait for the lock
+func getLockTimeout() (time.Duration, error) {
+	lockTimeout := os.Getenv("ATMOS_LOCK_TIMEOUT")
+	if len(lockTimeout) == 0 {
+		return 0, nil
+	}
+
+	timeout, err := time.ParseDuration(lockTimeout)
+	if err != nil || timeout < 0 {
+		return 0, errors.New(fmt.Sprintf("invalid value '%s' of the ENV var ATMOS_LOCK_TIMEOUT. It must be a duration (e.g. '5m')", lockTimeout))
+	}
+
+	return timeout, nil
+}
+
+// constructComponentLockFilePath returns the path to the lock file for the component directory and the stack.
+// The lock files are in the temp dir (not in the component directory), the file name is the SHA256 hash of the component directory and the stack
+func constructComponentLockFilePath(componentPath string, stack string) string {
+	hash := sha256.Sum256([]byte(componentPath + "\n" + stack))
+	return filepath.Join(os.TempDir(), "atmos", "locks", hex.EncodeToString(hash[:])+".lock")
+}
+
+// lockComponent acquires the advisory lock (flock on Unix, LockFileEx on Windows) for the component in the stack,
+// so two atmos processes on the same host don't operate on the same component and stack at the same time.
+// The OS releases the lock when the process exits, so a lock can't be left behind by a killed process.
+// It returns the function that releases the lock
+func lockComponent(componentPath string, component string, stack string) (func(), error) {
+	timeout, err := getLockTimeout()
+	if err != nil {
+		return nil, err
+	}
+
+	lockFilePath := constructComponentLockFilePath(componentPath, stack)
+	err = os.MkdirAll(filepath.Dir(lockFilePath), 0700)
+	if err != nil {
+		return nil, err
+	}
+
+	// The lock file is never deleted, so all the processes lock the same file
+	f, err := os.OpenFile(lockFilePath, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("error opening the lock file '%s': %v", lockFilePath, err))
+	}
+
+	deadline := time.Now().Add(timeout)
+
+	for {
+		locked, err := tryLockFile(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, errors.New(fmt.Sprintf("error locking the file '%s': %v", lockFilePath, err))
+		}
+
+		if locked {
+			return func() {
+				_ = unlockFile(f)
+				_ = f.Close()
+			}, nil
+		}
+
+		if time.Now().After(deadline) {
+			_ = f.Close()
+			return nil, errors.New(fmt.Sprintf("another atmos process is operating on the component '%s' in the stack '%s'. "+
+				"Use 'ATMOS_LOCK_TIMEOUT' ENV var (e.g. '5m') to wait for the lock",
+				component, stack))
+		}
+
+		time.Sleep(lockRetryInterval)
+	}
+}
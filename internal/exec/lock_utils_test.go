@@ -0,0 +1,78 @@
+package exec
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLockComponent(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	componentPath := t.TempDir()
+
+	unlock, err := lockComponent(componentPath, "vpc", "tenant1/ue2/dev")
+	assert.Nil(t, err)
+	assert.FileExists(t, constructComponentLockFilePath(componentPath, "tenant1/ue2/dev"))
+
+	// The lock file is not in the component directory
+	files, err := os.ReadDir(componentPath)
+	assert.Nil(t, err)
+	assert.Empty(t, files)
+
+	// The lock is held by this process
+	_, err = lockComponent(componentPath, "vpc", "tenant1/ue2/dev")
+	assert.NotNil(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "another atmos process"))
+
+	// The lock is per stack
+	unlockProd, err := lockComponent(componentPath, "vpc", "tenant1/ue2/prod")
+	assert.Nil(t, err)
+	unlockProd()
+
+	unlock()
+
+	// The lock is released
+	unlock, err = lockComponent(componentPath, "vpc", "tenant1/ue2/dev")
+	assert.Nil(t, err)
+	unlock()
+}
+
+func TestLockComponentWithTimeout(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	componentPath := t.TempDir()
+	t.Setenv("ATMOS_LOCK_TIMEOUT", "10s")
+
+	unlock, err := lockComponent(componentPath, "vpc", "dev")
+	assert.Nil(t, err)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		unlock()
+	}()
+
+	// Waits for the lock to be released
+	unlock2, err := lockComponent(componentPath, "vpc", "dev")
+	assert.Nil(t, err)
+	unlock2()
+
+	t.Setenv("ATMOS_LOCK_TIMEOUT", "5 minutes")
+	_, err = lockComponent(componentPath, "vpc", "dev")
+	assert.NotNil(t, err)
+}
+
+func TestLockComponentWithLeftoverLockFile(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	componentPath := t.TempDir()
+
+	// The lock file left by a process that exited is not locked
+	unlock, err := lockComponent(componentPath, "vpc", "dev")
+	assert.Nil(t, err)
+	unlock()
+	assert.FileExists(t, constructComponentLockFilePath(componentPath, "dev"))
+
+	unlock, err = lockComponent(componentPath, "vpc", "dev")
+	assert.Nil(t, err)
+	unlock()
+}
@@ -0,0 +1,24 @@
+//go:build !windows
+// +build !windows
+
+package exec
+
+import (
+	"os"
+	"syscall"
+)
+
+// tryLockFile tries to acquire the exclusive advisory lock on the file without blocking.
+// It returns false if the file is locked by another process (or by another open file in this process)
+func tryLockFile(f *os.File) (bool, error) {
+	err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// unlockFile releases the advisory lock on the file
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
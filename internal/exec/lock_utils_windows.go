@@ -0,0 +1,24 @@
+//go:build windows
+// +build windows
+
+package exec
+
+import (
+	"golang.org/x/sys/windows"
+	"os"
+)
+
+// tryLockFile tries to acquire the exclusive lock on the first byte of the file without blocking.
+// It returns false if the file is locked by another process (or by another open file in this process)
+func tryLockFile(f *os.File) (bool, error) {
+	err := windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, &windows.Overlapped{})
+	if err == windows.ERROR_LOCK_VIOLATION {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// unlockFile releases the lock on the first byte of the file
+func unlockFile(f *os.File) error {
+	return windows.UnlockFileEx(windows.Handle(f.Fd()), 0, 1, 0, &windows.Overlapped{})
+}
@@ -3,17 +3,38 @@ package exec
 import (
 	"fmt"
 	c "github.com/cloudposse/atmos/pkg/config"
-	"path"
+	u "github.com/cloudposse/atmos/pkg/utils"
+	"github.com/pkg/errors"
+	"path/filepath"
 )
 
-// constructTerraformComponentWorkingDir constructs the working dir for a terraform component in a stack
-func constructTerraformComponentWorkingDir(info c.ConfigAndStacksInfo) string {
-	return path.Join(
-		c.Config.BasePath,
-		c.Config.Components.Terraform.BasePath,
-		info.ComponentFolderPrefix,
-		info.FinalComponent,
-	)
+// ComponentPath returns the path to the component folder in the components base path and checks if the folder exists
+func ComponentPath(componentsBasePath string, componentFolderPrefix string, component string) (string, error) {
+	componentPath := filepath.Join(componentsBasePath, componentFolderPrefix, component)
+
+	componentPathExists, err := u.IsDirectory(componentPath)
+	if err != nil || !componentPathExists {
+		return "", errors.New(fmt.Sprintf("component '%s' not found in '%s'",
+			component,
+			filepath.Join(componentsBasePath, componentFolderPrefix),
+		))
+	}
+
+	return componentPath, nil
+}
+
+// TerraformWorkingDir returns the absolute path to the directory of the terraform component (e.g. `vpc` or `infra/vpc`)
+// where atmos executes the terraform commands, and checks if the directory exists.
+// The directory is in `components.terraform.base_path` (relative to `base_path` if it's provided)
+func TerraformWorkingDir(component string) (string, error) {
+	return ComponentPath(c.ProcessedConfig.TerraformDirAbsolutePath, "", component)
+}
+
+// HelmfileWorkingDir returns the absolute path to the directory of the helmfile component (e.g. `echo-server` or `apps/echo-server`)
+// where atmos executes the helmfile commands, and checks if the directory exists.
+// The directory is in `components.helmfile.base_path` (relative to `base_path` if it's provided)
+func HelmfileWorkingDir(component string) (string, error) {
+	return ComponentPath(c.ProcessedConfig.HelmfileDirAbsolutePath, "", component)
 }
 
 // constructTerraformComponentPlanfileName constructs the planfile name for a terraform component in a stack
@@ -41,31 +62,23 @@ func constructTerraformComponentVarfileName(info c.ConfigAndStacksInfo) string {
 }
 
 // constructTerraformComponentVarfilePath constructs the varfile path for a terraform component in a stack
-func constructTerraformComponentVarfilePath(info c.ConfigAndStacksInfo) string {
-	return path.Join(
-		constructTerraformComponentWorkingDir(info),
+// in the working dir of the component (see TerraformWorkingDir)
+func constructTerraformComponentVarfilePath(workingDir string, info c.ConfigAndStacksInfo) string {
+	return filepath.Join(
+		workingDir,
 		constructTerraformComponentVarfileName(info),
 	)
 }
 
 // constructTerraformComponentPlanfilePath constructs the planfile path for a terraform component in a stack
-func constructTerraformComponentPlanfilePath(info c.ConfigAndStacksInfo) string {
-	return path.Join(
-		constructTerraformComponentWorkingDir(info),
+// in the working dir of the component (see TerraformWorkingDir)
+func constructTerraformComponentPlanfilePath(workingDir string, info c.ConfigAndStacksInfo) string {
+	return filepath.Join(
+		workingDir,
 		constructTerraformComponentPlanfileName(info),
 	)
 }
 
-// constructHelmfileComponentWorkingDir constructs the working dir for a helmfile component in a stack
-func constructHelmfileComponentWorkingDir(info c.ConfigAndStacksInfo) string {
-	return path.Join(
-		c.Config.BasePath,
-		c.Config.Components.Helmfile.BasePath,
-		info.ComponentFolderPrefix,
-		info.FinalComponent,
-	)
-}
-
 // constructHelmfileComponentVarfileName constructs the varfile name for a helmfile component in a stack
 func constructHelmfileComponentVarfileName(info c.ConfigAndStacksInfo) string {
 	var varFile string
@@ -78,9 +91,22 @@ func constructHelmfileComponentVarfileName(info c.ConfigAndStacksInfo) string {
 }
 
 // constructHelmfileComponentVarfilePath constructs the varfile path for a helmfile component in a stack
-func constructHelmfileComponentVarfilePath(info c.ConfigAndStacksInfo) string {
-	return path.Join(
-		constructHelmfileComponentWorkingDir(info),
+// in the working dir of the component (see HelmfileWorkingDir)
+func constructHelmfileComponentVarfilePath(workingDir string, info c.ConfigAndStacksInfo) string {
+	return filepath.Join(
+		workingDir,
 		constructHelmfileComponentVarfileName(info),
 	)
 }
+
+// discoverTerraformVarFiles returns the var files for the stack found in the terraform component directory
+// (`<stack>.tfvars` and `<stack>.tfvars.json`, in this order). The returned paths are relative to the component directory
+func discoverTerraformVarFiles(componentPath string, stack string) []string {
+	var varFiles []string
+	for _, varFile := range []string{stack + ".tfvars", stack + ".tfvars.json"} {
+		if u.FileExists(filepath.Join(componentPath, varFile)) {
+			varFiles = append(varFiles, varFile)
+		}
+	}
+	return varFiles
+}
@@ -0,0 +1,80 @@
+package exec
+
+import (
+	c "github.com/cloudposse/atmos/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestComponentPath(t *testing.T) {
+	basePath := t.TempDir()
+	err := os.MkdirAll(path.Join(basePath, "infra", "vpc"), 0755)
+	assert.Nil(t, err)
+
+	componentPath, err := ComponentPath(basePath, "infra", "vpc")
+	assert.Nil(t, err)
+	assert.Equal(t, path.Join(basePath, "infra", "vpc"), componentPath)
+
+	componentPath, err = ComponentPath(basePath, "", "infra/vpc")
+	assert.Nil(t, err)
+	assert.Equal(t, path.Join(basePath, "infra", "vpc"), componentPath)
+}
+
+func TestComponentPathMissingComponent(t *testing.T) {
+	basePath := t.TempDir()
+
+	_, err := ComponentPath(basePath, "", "vpc")
+	assert.NotNil(t, err)
+	assert.Equal(t, "component 'vpc' not found in '"+basePath+"'", err.Error())
+
+	_, err = ComponentPath(basePath, "infra", "vpc")
+	assert.NotNil(t, err)
+	assert.Equal(t, "component 'vpc' not found in '"+path.Join(basePath, "infra")+"'", err.Error())
+}
+
+func TestWorkingDir(t *testing.T) {
+	processedConfig := c.ProcessedConfig
+	t.Cleanup(func() {
+		c.ProcessedConfig = processedConfig
+	})
+
+	basePath := t.TempDir()
+	assert.Nil(t, os.MkdirAll(path.Join(basePath, "components", "terraform", "infra", "vpc"), 0755))
+	assert.Nil(t, os.MkdirAll(path.Join(basePath, "components", "helmfile", "echo-server"), 0755))
+	c.ProcessedConfig.TerraformDirAbsolutePath = path.Join(basePath, "components", "terraform")
+	c.ProcessedConfig.HelmfileDirAbsolutePath = path.Join(basePath, "components", "helmfile")
+
+	workingDir, err := TerraformWorkingDir("infra/vpc")
+	assert.Nil(t, err)
+	assert.Equal(t, path.Join(basePath, "components", "terraform", "infra", "vpc"), workingDir)
+
+	workingDir, err = HelmfileWorkingDir("echo-server")
+	assert.Nil(t, err)
+	assert.Equal(t, path.Join(basePath, "components", "helmfile", "echo-server"), workingDir)
+
+	_, err = TerraformWorkingDir("echo-server")
+	assert.NotNil(t, err)
+}
+
+func TestDiscoverTerraformVarFiles(t *testing.T) {
+	componentPath := t.TempDir()
+	assert.Nil(t, discoverTerraformVarFiles(componentPath, "tenant1-ue2-dev"))
+
+	for _, f := range []string{"tenant1-ue2-dev.tfvars.json", "tenant1-ue2-dev.tfvars", "tenant1-ue2-prod.tfvars"} {
+		err := os.WriteFile(path.Join(componentPath, f), []byte{}, 0644)
+		assert.Nil(t, err)
+	}
+
+	assert.Equal(t, []string{"tenant1-ue2-dev.tfvars", "tenant1-ue2-dev.tfvars.json"}, discoverTerraformVarFiles(componentPath, "tenant1-ue2-dev"))
+}
+
+func TestConstructComponentFilePaths(t *testing.T) {
+	info := c.ConfigAndStacksInfo{ContextPrefix: "tenant1-ue2-dev", Component: "vpc", ComponentFolderPrefix: "infra"}
+	workingDir := path.Join("/components", "terraform", "infra", "vpc")
+
+	assert.Equal(t, path.Join(workingDir, "tenant1-ue2-dev-infra-vpc.terraform.tfvars.json"), constructTerraformComponentVarfilePath(workingDir, info))
+	assert.Equal(t, path.Join(workingDir, "tenant1-ue2-dev-infra-vpc.planfile"), constructTerraformComponentPlanfilePath(workingDir, info))
+	assert.Equal(t, path.Join(workingDir, "tenant1-ue2-dev-infra-vpc.helmfile.vars.yaml"), constructHelmfileComponentVarfilePath(workingDir, info))
+}
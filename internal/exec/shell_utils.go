@@ -1,16 +1,54 @@
 package exec
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"github.com/fatih/color"
+	"github.com/pkg/errors"
+	"io"
 	"os"
 	"os/exec"
+	"os/signal"
 	"runtime"
 	"strings"
+	"syscall"
+	"time"
 )
 
-// execCommand prints and executes the provided command with args and flags
-func execCommand(command string, args []string, dir string, env []string) error {
+// ExecResult is the result of an executed command
+type ExecResult struct {
+	// Command is the executed command with the args and flags
+	Command []string
+	// ExitCode is the exit code of the command (-1 if the command could not be started or was killed)
+	ExitCode int
+	// Stdout is the captured stdout of the command (only if the output capturing is enabled)
+	Stdout string
+	// Stderr is the captured stderr of the command (only if the output capturing is enabled)
+	Stderr string
+	// Duration is the time the command took to execute
+	Duration time.Duration
+}
+
+// ExecOptions are the options of the executed command
+type ExecOptions struct {
+	// DryRun prints the command, the working directory and the ENV vars, but does not execute the command
+	DryRun bool
+	// RedirectStdErr is the file to append the stderr of the command to (the stderr is still shown on the console)
+	RedirectStdErr string
+	// RedirectStdOut is the file to append the stdout of the command to (the stdout is still shown on the console)
+	RedirectStdOut string
+	// CaptureOutput captures the stdout and stderr of the command in the result (the output is still shown on the console)
+	CaptureOutput bool
+}
+
+// execCommand prints and executes the provided command with args and flags using the provided options
+func execCommand(command string, args []string, dir string, env []string, options ExecOptions) (ExecResult, error) {
+	result := ExecResult{
+		Command:  append([]string{command}, args...),
+		ExitCode: -1,
+	}
+
 	cmd := exec.Command(command, args...)
 	cmd.Env = append(os.Environ(), env...)
 	cmd.Dir = dir
@@ -19,9 +57,170 @@ func execCommand(command string, args []string, dir string, env []string) error
 	cmd.Stderr = os.Stderr
 
 	fmt.Println()
+	if options.DryRun {
+		color.Cyan("Dry run. Would execute command:\n")
+		fmt.Println(cmd.String())
+		fmt.Println(fmt.Sprintf("Working dir: %s", dir))
+		if len(env) > 0 {
+			fmt.Println("ENV vars:")
+			for _, v := range env {
+				fmt.Println(v)
+			}
+		}
+		result.ExitCode = 0
+		return result, nil
+	}
+
+	stdOutWriters := []io.Writer{os.Stdout}
+	stdErrWriters := []io.Writer{os.Stderr}
+
+	if len(options.RedirectStdOut) > 0 {
+		f, err := os.OpenFile(options.RedirectStdOut, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return result, errors.New(fmt.Sprintf("error opening the file '%s' to redirect stdout to: %v", options.RedirectStdOut, err))
+		}
+		defer f.Close()
+		stdOutWriters = append(stdOutWriters, f)
+	}
+
+	if len(options.RedirectStdErr) > 0 {
+		f, err := os.OpenFile(options.RedirectStdErr, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return result, errors.New(fmt.Sprintf("error opening the file '%s' to redirect stderr to: %v", options.RedirectStdErr, err))
+		}
+		defer f.Close()
+		stdErrWriters = append(stdErrWriters, f)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if options.CaptureOutput {
+		stdOutWriters = append(stdOutWriters, &stdout)
+		stdErrWriters = append(stdErrWriters, &stderr)
+	}
+
+	// The output is written to the console and teed to the redirect files and the capture buffers (if any)
+	if len(stdOutWriters) > 1 {
+		cmd.Stdout = io.MultiWriter(stdOutWriters...)
+	}
+	if len(stdErrWriters) > 1 {
+		cmd.Stderr = io.MultiWriter(stdErrWriters...)
+	}
+
+	timeout, err := getCommandTimeout()
+	if err != nil {
+		return result, err
+	}
+
 	color.Cyan("Executing command:\n")
 	fmt.Println(cmd.String())
-	return cmd.Run()
+
+	start := time.Now()
+	err = runCommand(cmd, timeout)
+	result.Duration = time.Since(start)
+
+	if cmd.ProcessState != nil {
+		result.ExitCode = cmd.ProcessState.ExitCode()
+	}
+	result.Stdout = stdout.String()
+	result.Stderr = stderr.String()
+
+	return result, err
+}
+
+// runCommand runs the command and waits for it to complete.
+// While the command is running, SIGINT and SIGTERM are relayed to the command (instead of terminating atmos),
+// so the command can clean up before exiting. The previous signal handling is restored when the command completes.
+// If `timeout` is not zero, the command is killed (with all its subprocesses) if it does not complete in the provided time
+func runCommand(cmd *exec.Cmd, timeout time.Duration) error {
+	ctx, cancel := context.Background(), func() {}
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		// Start the command in a new process group to be able to kill all its subprocesses
+		setProcessGroup(cmd)
+	}
+	defer cancel()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	err := cmd.Start()
+	if err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	for {
+		select {
+		case err = <-done:
+			return err
+		case sig := <-signals:
+			color.Yellow("\nReceived signal '%s'. Forwarding it to the command and waiting for the command to exit", sig)
+			err = forwardSignal(cmd, sig)
+			if err != nil {
+				color.Red("Error forwarding the signal '%s' to the command: %v", sig, err)
+			}
+		case <-ctx.Done():
+			err = killProcessGroup(cmd)
+			<-done
+			if err != nil {
+				return errors.New(fmt.Sprintf("the command '%s' timed out after %s and could not be killed: %v", cmd.String(), timeout, err))
+			}
+			return errors.New(fmt.Sprintf("the command '%s' timed out after %s", cmd.String(), timeout))
+		}
+	}
+}
+
+// getCommandTimeout returns the timeout for the executed commands from the `ATMOS_COMMAND_TIMEOUT` ENV var.
+// Zero (the default) means no timeout
+func getCommandTimeout() (time.Duration, error) {
+	commandTimeout := os.Getenv("ATMOS_COMMAND_TIMEOUT")
+	if len(commandTimeout) == 0 {
+		return 0, nil
+	}
+
+	timeout, err := time.ParseDuration(commandTimeout)
+	if err != nil || timeout < 0 {
+		return 0, errors.New(fmt.Sprintf("invalid value '%s' of the ENV var ATMOS_COMMAND_TIMEOUT. It must be a duration (e.g. '30m')", commandTimeout))
+	}
+
+	return timeout, nil
+}
+
+// execCommandWithRetries executes the provided command and retries it with exponential backoff if it fails.
+// The command is executed at most `retries + 1` times, the delay before each retry is doubled
+func execCommandWithRetries(
+	command string,
+	args []string,
+	dir string,
+	env []string,
+	options ExecOptions,
+	retries int,
+	delay time.Duration,
+) (ExecResult, error) {
+	result, err := execCommand(command, args, dir, env, options)
+
+	for attempt := 1; err != nil && attempt <= retries; attempt++ {
+		color.Yellow("\nCommand failed: %v\nRetrying in %s (attempt %d of %d)", err, delay, attempt, retries)
+		time.Sleep(delay)
+		delay = delay * 2
+		result, err = execCommand(command, args, dir, env, options)
+	}
+
+	return result, err
+}
+
+// checkCommandExists checks if the provided binary can be found in PATH (or by the provided path)
+func checkCommandExists(command string) error {
+	_, err := exec.LookPath(command)
+	if err != nil {
+		return errors.New(fmt.Sprintf("the binary '%s' could not be found: %v", command, err))
+	}
+	return nil
 }
 
 // execTerraformShellCommand executes `terraform shell` command by starting a new interactive shell
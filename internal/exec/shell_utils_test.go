@@ -0,0 +1,275 @@
+package exec
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// createFakeCommand creates a script that fails `failures` times and then succeeds.
+// The script counts the executions in the `count` file in the provided dir
+func createFakeCommand(t *testing.T, dir string, failures int) string {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake command is a shell script")
+	}
+
+	script := fmt.Sprintf(`#!/bin/sh
+count=$(cat count 2>/dev/null || echo 0)
+count=$((count + 1))
+echo $count > count
+if [ $count -le %d ]; then
+  exit 1
+fi
+exit 0
+`, failures)
+
+	scriptPath := path.Join(dir, "fake-terraform")
+	err := os.WriteFile(scriptPath, []byte(script), 0755)
+	assert.Nil(t, err)
+	return scriptPath
+}
+
+func readFakeCommandCount(t *testing.T, dir string) string {
+	count, err := os.ReadFile(path.Join(dir, "count"))
+	assert.Nil(t, err)
+	return string(count)
+}
+
+func TestExecCommandWithRetries(t *testing.T) {
+	dir := t.TempDir()
+	command := createFakeCommand(t, dir, 2)
+
+	_, err := execCommandWithRetries(command, []string{"init"}, dir, nil, ExecOptions{}, 3, time.Millisecond)
+	assert.Nil(t, err)
+	assert.Equal(t, "3\n", readFakeCommandCount(t, dir))
+}
+
+func TestExecCommandWithRetriesExhausted(t *testing.T) {
+	dir := t.TempDir()
+	command := createFakeCommand(t, dir, 3)
+
+	_, err := execCommandWithRetries(command, []string{"init"}, dir, nil, ExecOptions{}, 2, time.Millisecond)
+	assert.NotNil(t, err)
+	assert.Equal(t, "3\n", readFakeCommandCount(t, dir))
+}
+
+func TestExecCommandWithoutRetries(t *testing.T) {
+	dir := t.TempDir()
+	command := createFakeCommand(t, dir, 1)
+
+	_, err := execCommandWithRetries(command, []string{"init"}, dir, nil, ExecOptions{}, 0, time.Millisecond)
+	assert.NotNil(t, err)
+	assert.Equal(t, "1\n", readFakeCommandCount(t, dir))
+}
+
+func TestGetTerraformInitRetries(t *testing.T) {
+	retries, delay, err := getTerraformInitRetries()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, retries)
+	assert.Equal(t, 5*time.Second, delay)
+
+	t.Setenv("ATMOS_INIT_RETRIES", "3")
+	t.Setenv("ATMOS_INIT_RETRY_DELAY", "100ms")
+	retries, delay, err = getTerraformInitRetries()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, retries)
+	assert.Equal(t, 100*time.Millisecond, delay)
+
+	t.Setenv("ATMOS_INIT_RETRIES", "-1")
+	_, _, err = getTerraformInitRetries()
+	assert.NotNil(t, err)
+
+	t.Setenv("ATMOS_INIT_RETRIES", "1")
+	t.Setenv("ATMOS_INIT_RETRY_DELAY", "abc")
+	_, _, err = getTerraformInitRetries()
+	assert.NotNil(t, err)
+}
+
+func TestExecCommandRedirectStdErr(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test command is a shell script")
+	}
+
+	dir := t.TempDir()
+	stdErrFile := path.Join(dir, "stderr.log")
+
+	_, err := execCommand("sh", []string{"-c", "echo first error >&2"}, dir, nil, ExecOptions{RedirectStdErr: stdErrFile})
+	assert.Nil(t, err)
+	_, err = execCommand("sh", []string{"-c", "echo output; echo second error >&2"}, dir, nil, ExecOptions{RedirectStdErr: stdErrFile})
+	assert.Nil(t, err)
+
+	stdErr, err := os.ReadFile(stdErrFile)
+	assert.Nil(t, err)
+	assert.Equal(t, "first error\nsecond error\n", string(stdErr))
+}
+
+func TestExecCommandRedirectStdOut(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test command is a shell script")
+	}
+
+	dir := t.TempDir()
+	stdOutFile := path.Join(dir, "stdout.log")
+	stdErrFile := path.Join(dir, "stderr.log")
+
+	// Replace the console stdout to check that the output is written to both the console and the file
+	console, err := os.Create(path.Join(dir, "console.log"))
+	assert.Nil(t, err)
+	stdout := os.Stdout
+	os.Stdout = console
+	t.Cleanup(func() {
+		os.Stdout = stdout
+		_ = console.Close()
+	})
+
+	result, err := execCommand("sh", []string{"-c", "echo first output; echo error >&2; echo second output"}, dir, nil,
+		ExecOptions{RedirectStdOut: stdOutFile, RedirectStdErr: stdErrFile, CaptureOutput: true})
+	assert.Nil(t, err)
+
+	redirectedStdOut, err := os.ReadFile(stdOutFile)
+	assert.Nil(t, err)
+	assert.Equal(t, "first output\nsecond output\n", string(redirectedStdOut))
+	assert.Equal(t, string(redirectedStdOut), result.Stdout)
+
+	consoleOutput, err := os.ReadFile(console.Name())
+	assert.Nil(t, err)
+	assert.True(t, strings.HasSuffix(string(consoleOutput), string(redirectedStdOut)))
+
+	// The stderr is not written to the stdout file
+	redirectedStdErr, err := os.ReadFile(stdErrFile)
+	assert.Nil(t, err)
+	assert.Equal(t, "error\n", string(redirectedStdErr))
+}
+
+func TestExecCommandDryRun(t *testing.T) {
+	dir := t.TempDir()
+	command := createFakeCommand(t, dir, 0)
+
+	// Replace the console stdout to check the printed command
+	console, err := os.Create(path.Join(dir, "console.log"))
+	assert.Nil(t, err)
+	stdout := os.Stdout
+	os.Stdout = console
+	t.Cleanup(func() {
+		os.Stdout = stdout
+		_ = console.Close()
+	})
+
+	result, err := execCommand(command, []string{"plan", "-out=dev-vpc.planfile"}, dir, []string{"TF_IN_AUTOMATION=true"}, ExecOptions{DryRun: true})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, result.ExitCode)
+
+	consoleOutput, err := os.ReadFile(console.Name())
+	assert.Nil(t, err)
+	assert.Contains(t, string(consoleOutput), command+" plan -out=dev-vpc.planfile\n")
+	assert.Contains(t, string(consoleOutput), "Working dir: "+dir+"\n")
+	assert.Contains(t, string(consoleOutput), "TF_IN_AUTOMATION=true\n")
+
+	// The command is not executed
+	assert.NoFileExists(t, path.Join(dir, "count"))
+}
+
+func TestExecCommandWithoutRedirectStdErr(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test command is a shell script")
+	}
+
+	dir := t.TempDir()
+
+	_, err := execCommand("sh", []string{"-c", "echo error >&2"}, dir, nil, ExecOptions{})
+	assert.Nil(t, err)
+
+	files, err := os.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(files))
+}
+
+func TestExecCommandWithTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test command is a shell script")
+	}
+
+	dir := t.TempDir()
+
+	t.Setenv("ATMOS_COMMAND_TIMEOUT", "200ms")
+	start := time.Now()
+	// The subprocess (`sleep`) is killed with the process group
+	_, err := execCommand("sh", []string{"-c", "sleep 10; echo done > done"}, dir, nil, ExecOptions{})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "timed out after 200ms")
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.NoFileExists(t, path.Join(dir, "done"))
+
+	_, err = execCommand("sh", []string{"-c", "exit 0"}, dir, nil, ExecOptions{})
+	assert.Nil(t, err)
+
+	t.Setenv("ATMOS_COMMAND_TIMEOUT", "invalid")
+	_, err = execCommand("sh", []string{"-c", "exit 0"}, dir, nil, ExecOptions{})
+	assert.NotNil(t, err)
+}
+
+func TestExecCommandResult(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test command is a shell script")
+	}
+
+	dir := t.TempDir()
+
+	result, err := execCommand("sh", []string{"-c", "echo output; echo error >&2; exit 2"}, dir, nil, ExecOptions{CaptureOutput: true})
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, result.ExitCode)
+	assert.Equal(t, []string{"sh", "-c", "echo output; echo error >&2; exit 2"}, result.Command)
+	assert.Equal(t, "output\n", result.Stdout)
+	assert.Equal(t, "error\n", result.Stderr)
+	assert.Greater(t, result.Duration, time.Duration(0))
+
+	// The output is not captured by default
+	result, err = execCommand("sh", []string{"-c", "echo output"}, dir, nil, ExecOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, "", result.Stdout)
+
+	// The command does not exist
+	result, err = execCommand(path.Join(dir, "missing"), nil, dir, nil, ExecOptions{})
+	assert.NotNil(t, err)
+	assert.Equal(t, -1, result.ExitCode)
+}
+
+func TestTerraformPlanChangesDetected(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake command is a shell script")
+	}
+
+	dir := t.TempDir()
+	command := path.Join(dir, "fake-terraform")
+	err := os.WriteFile(command, []byte("#!/bin/sh\nexit $FAKE_TERRAFORM_EXIT_CODE\n"), 0755)
+	assert.Nil(t, err)
+
+	// The plan has changes
+	result, err := execCommand(command, []string{"plan", "-detailed-exitcode"}, dir, []string{"FAKE_TERRAFORM_EXIT_CODE=2"}, ExecOptions{})
+	err = processTerraformCommandResult("plan", result, err)
+	assert.True(t, errors.Is(err, ErrChangesDetected))
+
+	// The exit code 2 is a failure for other commands
+	result, err = execCommand(command, []string{"apply"}, dir, []string{"FAKE_TERRAFORM_EXIT_CODE=2"}, ExecOptions{})
+	err = processTerraformCommandResult("apply", result, err)
+	assert.NotNil(t, err)
+	assert.False(t, errors.Is(err, ErrChangesDetected))
+
+	// The plan failed
+	result, err = execCommand(command, []string{"plan", "-detailed-exitcode"}, dir, []string{"FAKE_TERRAFORM_EXIT_CODE=1"}, ExecOptions{})
+	err = processTerraformCommandResult("plan", result, err)
+	assert.NotNil(t, err)
+	assert.False(t, errors.Is(err, ErrChangesDetected))
+
+	// No changes
+	result, err = execCommand(command, []string{"plan", "-detailed-exitcode"}, dir, []string{"FAKE_TERRAFORM_EXIT_CODE=0"}, ExecOptions{})
+	err = processTerraformCommandResult("plan", result, err)
+	assert.Nil(t, err)
+}
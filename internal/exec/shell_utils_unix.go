@@ -0,0 +1,57 @@
+//go:build !windows
+// +build !windows
+
+package exec
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts the command in a new process group, so the command and all its subprocesses can be killed together
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcessGroup kills the process group of the command started with setProcessGroup
+func killProcessGroup(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
+
+// forwardSignal relays the signal to the process group of the command if the command was started in a new process group,
+// or to the command process otherwise.
+// SIGINT is not relayed to a command in the atmos process group when atmos runs in a terminal, since the terminal
+// already sends Ctrl-C to all the processes in the foreground process group (and the second SIGINT would force the command to quit)
+func forwardSignal(cmd *exec.Cmd, sig os.Signal) error {
+	if cmd.Process == nil {
+		return nil
+	}
+
+	s, ok := sig.(syscall.Signal)
+	if !ok {
+		return nil
+	}
+
+	if cmd.SysProcAttr != nil && cmd.SysProcAttr.Setpgid {
+		return syscall.Kill(-cmd.Process.Pid, s)
+	}
+
+	if s == syscall.SIGINT && isTerminal(os.Stdin) {
+		return nil
+	}
+
+	return cmd.Process.Signal(s)
+}
+
+// isTerminal checks if the file is a terminal (character device)
+func isTerminal(f *os.File) bool {
+	fileInfo, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fileInfo.Mode()&os.ModeCharDevice != 0
+}
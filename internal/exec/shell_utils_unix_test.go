@@ -0,0 +1,35 @@
+//go:build !windows
+// +build !windows
+
+package exec
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestExecCommandForwardsSignals(t *testing.T) {
+	dir := t.TempDir()
+
+	script := `trap 'echo terminated > terminated; kill $pid; exit 3' TERM
+sleep 10 &
+pid=$!
+wait $pid
+`
+
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		_ = syscall.Kill(os.Getpid(), syscall.SIGTERM)
+	}()
+
+	start := time.Now()
+	_, err := execCommand("sh", []string{"-c", script}, dir, nil, ExecOptions{})
+	// The command received the signal, cleaned up, and atmos waited for it to exit
+	assert.NotNil(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.FileExists(t, path.Join(dir, "terminated"))
+}
@@ -0,0 +1,27 @@
+//go:build windows
+// +build windows
+
+package exec
+
+import (
+	"os"
+	"os/exec"
+)
+
+// setProcessGroup does nothing on Windows, process groups are not supported
+func setProcessGroup(cmd *exec.Cmd) {
+}
+
+// killProcessGroup kills the process of the command (its subprocesses are not killed on Windows)
+func killProcessGroup(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return cmd.Process.Kill()
+}
+
+// forwardSignal does nothing on Windows. Ctrl-C is sent by the console to all the processes attached to it (including the command),
+// and sending signals to other processes is not supported
+func forwardSignal(cmd *exec.Cmd, sig os.Signal) error {
+	return nil
+}
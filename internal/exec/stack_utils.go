@@ -0,0 +1,450 @@
+package exec
+
+import (
+	"fmt"
+	c "github.com/cloudposse/atmos/pkg/config"
+	g "github.com/cloudposse/atmos/pkg/globals"
+	s "github.com/cloudposse/atmos/pkg/stack"
+	u "github.com/cloudposse/atmos/pkg/utils"
+	"github.com/pkg/errors"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// maxStackSuggestions is the max number of stack names suggested when the provided stack is not found
+	maxStackSuggestions = 3
+	// maxStackSuggestionDistance is the max Levenshtein distance between the provided stack and a suggested stack
+	maxStackSuggestionDistance = 3
+)
+
+// ListStacks returns a sorted list of all logical stack names defined in the stack config files
+func ListStacks() ([]string, error) {
+	stacksMap, err := processAllStacks()
+	if err != nil {
+		return nil, err
+	}
+
+	return getStackNamesFromStacksMap(stacksMap), nil
+}
+
+// ListComponents returns a sorted list of all terraform and helmfile components defined in the provided logical stack
+func ListComponents(stack string) ([]string, error) {
+	if len(stack) == 0 {
+		return nil, errors.New("stack must be provided and must not be empty")
+	}
+
+	stacksMap, err := processAllStacks()
+	if err != nil {
+		return nil, err
+	}
+
+	stack = c.ResolveStackAlias(stack)
+
+	stackNames := getStackNamesFromStacksMap(stacksMap)
+	if !u.SliceContainsString(stackNames, stack) {
+		return nil, errors.New(stackNotFoundMessage(stack, stackNames))
+	}
+
+	var components []string
+	forEachComponentInStacksMap(stacksMap, func(stackFileName string, componentType string, component string, componentSection map[string]interface{}) {
+		if stackName, ok := getComponentStackName(stackFileName, componentSection); ok && stackName == stack {
+			components = append(components, component)
+		}
+	})
+
+	components = u.UniqueStrings(components)
+	sort.Strings(components)
+	return components, nil
+}
+
+// StacksUsingComponent returns a sorted list of all logical stack names that define the provided terraform or helmfile component.
+// The component name must match exactly
+func StacksUsingComponent(component string) ([]string, error) {
+	if len(component) == 0 {
+		return nil, errors.New("component must be provided and must not be empty")
+	}
+
+	stacksMap, err := processAllStacks()
+	if err != nil {
+		return nil, err
+	}
+
+	var stackNames []string
+	forEachComponentInStacksMap(stacksMap, func(stackFileName string, componentType string, componentName string, componentSection map[string]interface{}) {
+		if componentName != component {
+			return
+		}
+		if stackName, ok := getComponentStackName(stackFileName, componentSection); ok {
+			stackNames = append(stackNames, stackName)
+		}
+	})
+
+	stackNames = u.UniqueStrings(stackNames)
+	sort.Strings(stackNames)
+	return stackNames, nil
+}
+
+// StackFile returns the absolute path to the stack config file that defines the provided logical stack.
+// If the stack is defined in more than one stack config file, an error listing all the files is returned
+func StackFile(stack string) (string, error) {
+	if len(stack) == 0 {
+		return "", errors.New("stack must be provided and must not be empty")
+	}
+
+	stacksMap, err := processAllStacks()
+	if err != nil {
+		return "", err
+	}
+
+	stack = c.ResolveStackAlias(stack)
+
+	var stackFileNames []string
+	forEachComponentInStacksMap(stacksMap, func(stackFileName string, componentType string, component string, componentSection map[string]interface{}) {
+		if stackName, ok := getComponentStackName(stackFileName, componentSection); ok && stackName == stack {
+			stackFileNames = append(stackFileNames, stackFileName)
+		}
+	})
+	stackFileNames = u.UniqueStrings(stackFileNames)
+
+	if len(stackFileNames) == 0 {
+		return "", errors.New(stackNotFoundMessage(stack, getStackNamesFromStacksMap(stacksMap)))
+	}
+
+	var stackFiles []string
+	for _, stackFileName := range stackFileNames {
+		stackFiles = append(stackFiles, getStackConfigFileAbsolutePath(stackFileName))
+	}
+	sort.Strings(stackFiles)
+
+	if len(stackFiles) > 1 {
+		return "", errors.New(fmt.Sprintf("the stack '%s' is defined in more than one stack config file:\n%s",
+			stack,
+			strings.Join(stackFiles, "\n"),
+		))
+	}
+
+	return stackFiles[0], nil
+}
+
+// FindOrphanedStacks returns a map of the stack config files (absolute paths) that define components
+// for which a logical stack name can't be calculated using the stack name pattern to the reasons (e.g. a token in the pattern is not provided in the vars)
+func FindOrphanedStacks() (map[string]string, error) {
+	stacksMap, err := processAllStacks()
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[string]string{}
+	for stackFileName, reason := range findOrphanedStacks(stacksMap) {
+		res[getStackConfigFileAbsolutePath(stackFileName)] = reason
+	}
+
+	return res, nil
+}
+
+// findOrphanedStacks returns a map of the stack config file names (that don't produce valid logical stack names)
+// to the error of the first (sorted by the component type and name) component that does not produce a valid stack name
+func findOrphanedStacks(stacksMap map[string]interface{}) map[string]string {
+	componentErrors := map[string]map[string]string{}
+
+	forEachComponentInStacksMap(stacksMap, func(stackFileName string, componentType string, component string, componentSection map[string]interface{}) {
+		stackName, err := c.StackNameFunc(stackFileName, componentSection)
+		if err == nil && len(stackName) > 0 {
+			return
+		}
+
+		reason := fmt.Sprintf("the component '%s' does not produce a stack name", component)
+		if err != nil {
+			reason = err.Error()
+		}
+
+		if _, ok := componentErrors[stackFileName]; !ok {
+			componentErrors[stackFileName] = map[string]string{}
+		}
+		componentErrors[stackFileName][componentType+"/"+component] = reason
+	})
+
+	res := map[string]string{}
+	for stackFileName, errs := range componentErrors {
+		var components []string
+		for component := range errs {
+			components = append(components, component)
+		}
+		sort.Strings(components)
+		res[stackFileName] = errs[components[0]]
+	}
+
+	return res
+}
+
+// getStackConfigFileAbsolutePath returns the absolute path to the stack config file
+// from the stack config file name (the file path relative to the stacks base path without the extension)
+func getStackConfigFileAbsolutePath(stackFileName string) string {
+	for i, relativePath := range c.ProcessedConfig.StackConfigFilesRelativePaths {
+		if strings.TrimSuffix(relativePath, filepath.Ext(relativePath)) == stackFileName {
+			return c.ProcessedConfig.StackConfigFilesAbsolutePaths[i]
+		}
+	}
+	return path.Join(c.ProcessedConfig.StacksBaseAbsolutePath, stackFileName+g.DefaultStackConfigFileExtension)
+}
+
+// processAllStacks finds and processes all stack config files and returns a map of stack configs
+func processAllStacks() (map[string]interface{}, error) {
+	return processAllStacksWithSourceMetadata(false)
+}
+
+// processAllStacksWithSourceMetadata finds and processes all stack config files and returns a map of stack configs.
+// If `includeSourceMetadata` is `true`, each component gets the `_metadata` section with the stack config files that contributed its values
+func processAllStacksWithSourceMetadata(includeSourceMetadata bool) (map[string]interface{}, error) {
+	err := c.InitConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.ProcessConfigForSpacelift()
+	if err != nil {
+		return nil, err
+	}
+
+	_, stacksMap, err := s.ProcessYAMLConfigFilesWithOptions(
+		c.ProcessedConfig.StacksBaseAbsolutePath,
+		c.ProcessedConfig.StackConfigFilesAbsolutePaths,
+		false,
+		false,
+		getStackProcessOptions(includeSourceMetadata))
+	if err != nil {
+		return nil, err
+	}
+
+	return stacksMap, nil
+}
+
+// getStackProcessOptions returns the options of the stack config processing from the CLI config
+func getStackProcessOptions(includeSourceMetadata bool) s.ProcessOptions {
+	return s.ProcessOptions{
+		IncludeSourceMetadata: includeSourceMetadata,
+		ListMergeStrategy:     c.Config.Settings.ListMergeStrategy,
+		TerraformCommand:      c.Config.Components.Terraform.Command,
+		HelmfileCommand:       c.Config.Components.Helmfile.Command,
+	}
+}
+
+// forEachComponentInStacksMap calls the provided function for each terraform and helmfile component in each stack config
+func forEachComponentInStacksMap(
+	stacksMap map[string]interface{},
+	fn func(stackFileName string, componentType string, component string, componentSection map[string]interface{})) {
+
+	for stackFileName, stackSection := range stacksMap {
+		stackSectionMap, ok := stackSection.(map[interface{}]interface{})
+		if !ok {
+			continue
+		}
+		componentsSection, ok := stackSectionMap["components"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		for _, componentType := range []string{"terraform", "helmfile"} {
+			componentTypeSection, ok := componentsSection[componentType].(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			for component, componentSection := range componentTypeSection {
+				componentSectionMap, ok := componentSection.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				fn(stackFileName, componentType, component, componentSectionMap)
+			}
+		}
+	}
+}
+
+// getComponentStackName calculates the logical stack name of the component using `StackNameFunc`
+func getComponentStackName(stackFileName string, componentSection map[string]interface{}) (string, bool) {
+	stackName, err := c.StackNameFunc(stackFileName, componentSection)
+	if err != nil || len(stackName) == 0 {
+		return "", false
+	}
+
+	return stackName, true
+}
+
+// getStackNamesFromStacksMap calculates the logical stack names from the context (vars) of the components in the stacks
+func getStackNamesFromStacksMap(stacksMap map[string]interface{}) []string {
+	var stackNames []string
+
+	forEachComponentInStacksMap(stacksMap, func(stackFileName string, componentType string, component string, componentSection map[string]interface{}) {
+		if stackName, ok := getComponentStackName(stackFileName, componentSection); ok {
+			stackNames = append(stackNames, stackName)
+		}
+	})
+
+	stackNames = u.UniqueStrings(stackNames)
+	sort.Strings(stackNames)
+	return stackNames
+}
+
+// FindStackAndComponentNameCollisions finds and processes all stack config files and returns a sorted list of the collisions
+// of the logical stack names and the stack config file names with the names of the terraform and helmfile component directories
+func FindStackAndComponentNameCollisions() ([]string, error) {
+	stacksMap, err := processAllStacks()
+	if err != nil {
+		return nil, err
+	}
+
+	return findStackAndComponentNameCollisions(stacksMap)
+}
+
+// findStackAndComponentNameCollisions returns a sorted list of the collisions of the logical stack names and the stack config file names
+// with the names of the terraform and helmfile component directories
+func findStackAndComponentNameCollisions(stacksMap map[string]interface{}) ([]string, error) {
+	componentDirs := map[string]string{}
+	componentsBasePaths := []struct {
+		path         string
+		filePatterns []string
+	}{
+		{c.ProcessedConfig.TerraformDirAbsolutePath, []string{"*.tf", "*.tf.json"}},
+		{c.ProcessedConfig.HelmfileDirAbsolutePath, []string{"helmfile.yaml"}},
+	}
+	for _, componentsBasePath := range componentsBasePaths {
+		dirs, err := getComponentDirs(componentsBasePath.path, componentsBasePath.filePatterns)
+		if err != nil {
+			return nil, err
+		}
+		for relativeDir, absoluteDir := range dirs {
+			componentDirs[relativeDir] = absoluteDir
+		}
+	}
+
+	if len(componentDirs) == 0 {
+		return nil, nil
+	}
+
+	// Stack names (logical names and stack config file names) with the stack config files where they are defined
+	stackFileNames := map[string][]string{}
+	for stackFileName := range stacksMap {
+		stackFileNames[stackFileName] = append(stackFileNames[stackFileName], stackFileName)
+	}
+	forEachComponentInStacksMap(stacksMap, func(stackFileName string, componentType string, component string, componentSection map[string]interface{}) {
+		if stackName, ok := getComponentStackName(stackFileName, componentSection); ok && stackName != stackFileName {
+			stackFileNames[stackName] = append(stackFileNames[stackName], stackFileName)
+		}
+	})
+
+	var collisions []string
+	for stackName, fileNames := range stackFileNames {
+		componentDir, ok := componentDirs[stackName]
+		if !ok {
+			continue
+		}
+		for _, stackFileName := range u.UniqueStrings(fileNames) {
+			collisions = append(collisions, fmt.Sprintf("the stack '%s' defined in the stack config file '%s' has the same name as the component directory '%s'",
+				stackName,
+				getStackConfigFileAbsolutePath(stackFileName),
+				componentDir,
+			))
+		}
+	}
+
+	sort.Strings(collisions)
+	return collisions, nil
+}
+
+// getComponentDirs returns a map of the component directories in the components base path (relative paths with forward slashes)
+// to their absolute paths. A component directory contains at least one file matching the provided patterns (e.g. '*.tf').
+// The subdirectories of the component directories (e.g. 'vpc/modules') and the hidden directories (e.g. '.terraform') are skipped
+func getComponentDirs(componentsBasePath string, componentFilePatterns []string) (map[string]string, error) {
+	dirs := map[string]string{}
+
+	if len(componentsBasePath) == 0 {
+		return dirs, nil
+	}
+	exists, err := u.IsDirectory(componentsBasePath)
+	if err != nil || !exists {
+		return dirs, nil
+	}
+
+	err = filepath.WalkDir(componentsBasePath, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() || p == componentsBasePath {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+
+		isComponentDir := false
+		for _, pattern := range componentFilePatterns {
+			matches, err := filepath.Glob(filepath.Join(p, pattern))
+			if err != nil {
+				return err
+			}
+			if len(matches) > 0 {
+				isComponentDir = true
+				break
+			}
+		}
+		if !isComponentDir {
+			return nil
+		}
+
+		relativeDir, err := filepath.Rel(componentsBasePath, p)
+		if err != nil {
+			return err
+		}
+		dirs[filepath.ToSlash(relativeDir)] = p
+		return filepath.SkipDir
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dirs, nil
+}
+
+// suggestStackNames returns the stack names closest to the provided stack (by Levenshtein distance)
+func suggestStackNames(stack string, stackNames []string) []string {
+	type suggestion struct {
+		name     string
+		distance int
+	}
+
+	var suggestions []suggestion
+	for _, stackName := range stackNames {
+		distance := u.LevenshteinDistance(stack, stackName)
+		if distance <= maxStackSuggestionDistance {
+			suggestions = append(suggestions, suggestion{name: stackName, distance: distance})
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].distance < suggestions[j].distance
+	})
+
+	var res []string
+	for i := 0; i < len(suggestions) && i < maxStackSuggestions; i++ {
+		res = append(res, suggestions[i].name)
+	}
+	return res
+}
+
+// stackNotFoundMessage returns the error message for a stack not found in the stack config files,
+// including the closest matching stack names if any
+func stackNotFoundMessage(stack string, stackNames []string) string {
+	message := fmt.Sprintf("stack '%s' not found", stack)
+
+	suggestions := suggestStackNames(stack, stackNames)
+	if len(suggestions) > 0 {
+		message = fmt.Sprintf("%s\ndid you mean '%s'?", message, strings.Join(suggestions, "', '"))
+	}
+
+	return message
+}
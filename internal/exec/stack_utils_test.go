@@ -0,0 +1,310 @@
+package exec
+
+import (
+	c "github.com/cloudposse/atmos/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupTestStacks creates the CLI config and the stack config files in a temp dir and changes the current dir to it.
+// The current dir and the CLI config are restored after the test
+func setupTestStacks(t *testing.T, atmosConfig string, stackConfigs map[string]string) string {
+	config := c.Config
+	processedConfig := c.ProcessedConfig
+	cwd, err := os.Getwd()
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_ = os.Chdir(cwd)
+		c.Config = config
+		c.ProcessedConfig = processedConfig
+	})
+
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "atmos.yaml"), []byte(atmosConfig), 0644))
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "stacks"), 0755))
+	for stackFile, stackConfig := range stackConfigs {
+		stackFilePath := filepath.Join(dir, "stacks", stackFile)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(stackFilePath), 0755))
+		assert.Nil(t, os.WriteFile(stackFilePath, []byte(stackConfig), 0644))
+	}
+	assert.Nil(t, os.Chdir(dir))
+
+	// Resolve the symlinks in the temp dir path (e.g. on macOS), so the paths can be compared with the paths calculated by atmos
+	dir, err = os.Getwd()
+	assert.Nil(t, err)
+	return dir
+}
+
+const testAtmosConfig = `
+stacks:
+  base_path: "stacks"
+  included_paths:
+    - "orgs/**/*"
+  name_pattern: "{tenant}-{stage}"
+`
+
+var testStackConfigs = map[string]string{
+	"orgs/tenant1/dev.yaml": `
+vars:
+  tenant: tenant1
+  stage: dev
+components:
+  terraform:
+    vpc:
+      vars: {}
+    eks:
+      vars: {}
+  helmfile:
+    echo-server:
+      vars: {}
+`,
+	"orgs/tenant1/prod.yaml": `
+vars:
+  tenant: tenant1
+  stage: prod
+components:
+  terraform:
+    vpc:
+      vars: {}
+`,
+}
+
+func TestFindStackAndComponentNameCollisions(t *testing.T) {
+	config := c.Config
+	processedConfig := c.ProcessedConfig
+	t.Cleanup(func() {
+		c.Config = config
+		c.ProcessedConfig = processedConfig
+	})
+
+	dir := t.TempDir()
+	for _, f := range []string{
+		"terraform/vpc/main.tf",
+		"terraform/vpc/modules/subnets/main.tf",
+		"terraform/infra/dev/main.tf.json",
+		"terraform/docs/README.md",
+		"terraform/vpc/.terraform/modules/main.tf",
+		"helmfile/echo-server/helmfile.yaml",
+	} {
+		assert.Nil(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(f)), 0755))
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, f), []byte{}, 0644))
+	}
+
+	c.Config.Stacks.NamePattern = "{stage}"
+	c.ProcessedConfig.TerraformDirAbsolutePath = filepath.Join(dir, "terraform")
+	c.ProcessedConfig.HelmfileDirAbsolutePath = filepath.Join(dir, "helmfile")
+	c.ProcessedConfig.StacksBaseAbsolutePath = filepath.Join(dir, "stacks")
+
+	stack := func(stage string) map[interface{}]interface{} {
+		return map[interface{}]interface{}{
+			"components": map[string]interface{}{
+				"terraform": map[string]interface{}{
+					"vpc": map[string]interface{}{
+						"vars": map[interface{}]interface{}{"stage": stage},
+					},
+				},
+			},
+		}
+	}
+
+	// No collisions. The subdirectories of the components and the directories without component files are not components
+	stacksMap := map[string]interface{}{"orgs/prod": stack("prod"), "docs": stack("vpc/modules")}
+	collisions, err := findStackAndComponentNameCollisions(stacksMap)
+	assert.Nil(t, err)
+	assert.Empty(t, collisions)
+
+	// The stack config file name and the logical stack name collide with the component directories
+	stacksMap = map[string]interface{}{"vpc": stack("prod"), "orgs/dev": stack("infra/dev"), "echo-server": stack("staging")}
+	collisions, err = findStackAndComponentNameCollisions(stacksMap)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"the stack 'echo-server' defined in the stack config file '" + filepath.Join(dir, "stacks", "echo-server.yaml") +
+			"' has the same name as the component directory '" + filepath.Join(dir, "helmfile", "echo-server") + "'",
+		"the stack 'infra/dev' defined in the stack config file '" + filepath.Join(dir, "stacks", "orgs/dev.yaml") +
+			"' has the same name as the component directory '" + filepath.Join(dir, "terraform", "infra/dev") + "'",
+		"the stack 'vpc' defined in the stack config file '" + filepath.Join(dir, "stacks", "vpc.yaml") +
+			"' has the same name as the component directory '" + filepath.Join(dir, "terraform", "vpc") + "'",
+	}, collisions)
+}
+
+func TestGetComponentStackNameWithCustomStackNameFunc(t *testing.T) {
+	stackNameFunc := c.StackNameFunc
+	namePattern := c.Config.Stacks.NamePattern
+	t.Cleanup(func() {
+		c.StackNameFunc = stackNameFunc
+		c.Config.Stacks.NamePattern = namePattern
+	})
+
+	componentSection := map[string]interface{}{
+		"vars": map[interface{}]interface{}{"tenant": "tenant1", "stage": "dev"},
+	}
+
+	c.Config.Stacks.NamePattern = "{tenant}-{stage}"
+	stackName, ok := getComponentStackName("orgs/tenant1/dev", componentSection)
+	assert.True(t, ok)
+	assert.Equal(t, "tenant1-dev", stackName)
+
+	// Custom naming from the stack config file name
+	c.StackNameFunc = func(filePath string, contents map[string]interface{}) (string, error) {
+		return strings.ReplaceAll(filePath, "/", "."), nil
+	}
+	stackName, ok = getComponentStackName("orgs/tenant1/dev", componentSection)
+	assert.True(t, ok)
+	assert.Equal(t, "orgs.tenant1.dev", stackName)
+
+	stackNames := getStackNamesFromStacksMap(map[string]interface{}{
+		"orgs/tenant1/dev": map[interface{}]interface{}{
+			"components": map[string]interface{}{
+				"terraform": map[string]interface{}{"vpc": componentSection},
+			},
+		},
+	})
+	assert.Equal(t, []string{"orgs.tenant1.dev"}, stackNames)
+}
+
+func TestFindOrphanedStacks(t *testing.T) {
+	namePattern := c.Config.Stacks.NamePattern
+	t.Cleanup(func() {
+		c.Config.Stacks.NamePattern = namePattern
+	})
+	c.Config.Stacks.NamePattern = "{tenant}-{stage}"
+
+	stack := func(vars map[interface{}]interface{}) map[interface{}]interface{} {
+		return map[interface{}]interface{}{
+			"components": map[string]interface{}{
+				"terraform": map[string]interface{}{
+					"vpc": map[string]interface{}{"vars": vars},
+				},
+			},
+		}
+	}
+
+	stacksMap := map[string]interface{}{
+		"orgs/tenant1/dev": stack(map[interface{}]interface{}{"tenant": "tenant1", "stage": "dev"}),
+		"orgs/legacy/prod": stack(map[interface{}]interface{}{"stage": "prod"}),
+	}
+
+	orphanedStacks := findOrphanedStacks(stacksMap)
+	assert.Equal(t, map[string]string{
+		"orgs/legacy/prod": "the stack name pattern '{tenant}-{stage}' specifies '{tenant}', but 'tenant' is not provided in the stack orgs/legacy/prod",
+	}, orphanedStacks)
+}
+
+func TestFindOrphanedStacksReturnsStackFiles(t *testing.T) {
+	stackConfigs := map[string]string{
+		"orgs/legacy/prod.yaml": `
+vars:
+  stage: prod
+components:
+  terraform:
+    vpc:
+      vars: {}
+`,
+	}
+	for stackFile, stackConfig := range testStackConfigs {
+		stackConfigs[stackFile] = stackConfig
+	}
+	dir := setupTestStacks(t, testAtmosConfig, stackConfigs)
+
+	orphanedStacks, err := FindOrphanedStacks()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		filepath.Join(dir, "stacks", "orgs", "legacy", "prod.yaml"): "the stack name pattern '{tenant}-{stage}' specifies '{tenant}', " +
+			"but 'tenant' is not provided in the stack orgs/legacy/prod",
+	}, orphanedStacks)
+}
+
+func TestStackNotFoundMessage(t *testing.T) {
+	stackNames := []string{"tenant1-ue2-dev", "tenant1-ue2-prod", "tenant1-ue2-staging", "tenant2-ue2-dev"}
+
+	assert.Equal(t, []string{"tenant1-ue2-dev", "tenant2-ue2-dev"}, suggestStackNames("tenant1-ue2-dv", stackNames))
+	assert.Empty(t, suggestStackNames("unknown", stackNames))
+
+	assert.Equal(t, "stack 'tenant1-ue2-dv' not found\ndid you mean 'tenant1-ue2-dev', 'tenant2-ue2-dev'?",
+		stackNotFoundMessage("tenant1-ue2-dv", stackNames))
+	assert.Equal(t, "stack 'unknown' not found", stackNotFoundMessage("unknown", stackNames))
+}
+
+func TestListComponents(t *testing.T) {
+	setupTestStacks(t, testAtmosConfig, testStackConfigs)
+
+	components, err := ListComponents("tenant1-dev")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"echo-server", "eks", "vpc"}, components)
+
+	components, err = ListComponents("tenant1-prod")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"vpc"}, components)
+
+	_, err = ListComponents("tenant1-dv")
+	assert.NotNil(t, err)
+	assert.Equal(t, "stack 'tenant1-dv' not found\ndid you mean 'tenant1-dev'?", err.Error())
+
+	_, err = ListComponents("")
+	assert.NotNil(t, err)
+}
+
+func TestStacksUsingComponent(t *testing.T) {
+	setupTestStacks(t, testAtmosConfig, testStackConfigs)
+
+	stacks, err := StacksUsingComponent("vpc")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"tenant1-dev", "tenant1-prod"}, stacks)
+
+	stacks, err = StacksUsingComponent("echo-server")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"tenant1-dev"}, stacks)
+
+	stacks, err = StacksUsingComponent("unknown")
+	assert.Nil(t, err)
+	assert.Empty(t, stacks)
+
+	_, err = StacksUsingComponent("")
+	assert.NotNil(t, err)
+}
+
+func TestStackFile(t *testing.T) {
+	stackConfigs := map[string]string{
+		"orgs/tenant1/dev-eks.yaml": `
+vars:
+  tenant: tenant1
+  stage: dev
+components:
+  terraform:
+    eks:
+      vars: {}
+`,
+	}
+	for stackFile, stackConfig := range testStackConfigs {
+		stackConfigs[stackFile] = stackConfig
+	}
+	dir := setupTestStacks(t, testAtmosConfig+`
+  aliases:
+    prod: tenant1-prod
+`, stackConfigs)
+
+	stackFile, err := StackFile("tenant1-prod")
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(dir, "stacks", "orgs", "tenant1", "prod.yaml"), stackFile)
+
+	// The stack aliases are resolved
+	stackFile, err = StackFile("prod")
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(dir, "stacks", "orgs", "tenant1", "prod.yaml"), stackFile)
+
+	_, err = StackFile("tenant1-dev")
+	assert.NotNil(t, err)
+	assert.Equal(t, "the stack 'tenant1-dev' is defined in more than one stack config file:\n"+
+		filepath.Join(dir, "stacks", "orgs", "tenant1", "dev-eks.yaml")+"\n"+
+		filepath.Join(dir, "stacks", "orgs", "tenant1", "dev.yaml"), err.Error())
+
+	_, err = StackFile("tenant2-prod")
+	assert.NotNil(t, err)
+	assert.Equal(t, "stack 'tenant2-prod' not found\ndid you mean 'tenant1-prod'?", err.Error())
+
+	_, err = StackFile("")
+	assert.NotNil(t, err)
+}
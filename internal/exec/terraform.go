@@ -8,14 +8,28 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"os"
-	"path"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 )
 
 const (
 	autoApproveFlag = "-auto-approve"
+
+	// terraformPlanChangesExitCode is the exit code of `terraform plan -detailed-exitcode` when the plan has changes
+	terraformPlanChangesExitCode = 2
+
+	// terraformDefaultWorkspace is the workspace used when `components.terraform.use_workspaces` is `false`
+	terraformDefaultWorkspace = "default"
 )
 
+// terraformProvisionSubCommands are the terraform subcommands that can't be executed for abstract components (`metadata.type: abstract`)
+var terraformProvisionSubCommands = []string{"apply", "deploy"}
+
+// ErrChangesDetected is returned when `terraform plan -detailed-exitcode` succeeds and the plan has changes
+var ErrChangesDetected = errors.New("terraform plan detected changes")
+
 // ExecuteTerraform executes terraform commands
 func ExecuteTerraform(cmd *cobra.Command, args []string) error {
 	info, err := processArgsConfigAndStacks("terraform", cmd, args)
@@ -36,40 +50,71 @@ func ExecuteTerraform(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// Check if the component is allowed to be executed (`components.allowlist` and `components.denylist` config)
+	err = checkComponentAllowed(info.ComponentFromArg)
+	if err != nil {
+		return err
+	}
+
+	// In strict mode, check that the binary to execute can be found
+	if c.Config.Settings.StrictMode {
+		err = checkCommandExists(info.Command)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Check if the component (or base component) exists as Terraform component
-	componentPath := path.Join(c.ProcessedConfig.TerraformDirAbsolutePath, info.ComponentFolderPrefix, info.FinalComponent)
-	componentPathExists, err := utils.IsDirectory(componentPath)
-	if err != nil || !componentPathExists {
-		return errors.New(fmt.Sprintf("Component '%s' does not exist in '%s'",
-			info.FinalComponent,
-			path.Join(c.ProcessedConfig.TerraformDirAbsolutePath, info.ComponentFolderPrefix),
-		))
+	componentPath, err := TerraformWorkingDir(filepath.Join(info.ComponentFolderPrefix, info.FinalComponent))
+	if err != nil {
+		return err
 	}
 
 	// Check if the component is allowed to be provisioned (`metadata.type` attribute)
-	if (info.SubCommand == "apply" || info.SubCommand == "deploy") && info.ComponentIsAbstract {
-		return errors.New(fmt.Sprintf("Abstract component '%s' cannot be provisioned since it's explicitly prohibited from being deployed "+
-			"by 'metadata.type: abstract' attribute", path.Join(info.ComponentFolderPrefix, info.Component)))
+	err = checkComponentNotAbstract(info, terraformProvisionSubCommands)
+	if err != nil {
+		return err
+	}
+
+	// Prevent concurrent atmos runs against the same component and stack
+	if !info.DryRun {
+		unlock, err := lockComponent(componentPath, info.ComponentFromArg, info.Stack)
+		if err != nil {
+			return err
+		}
+		defer unlock()
 	}
 
 	varFile := constructTerraformComponentVarfileName(info)
 	planFile := constructTerraformComponentPlanfileName(info)
 
 	if info.SubCommand == "clean" {
+		if info.DryRun {
+			color.Cyan("Dry run. The files will not be deleted\n")
+		}
+
 		fmt.Println("Deleting '.terraform' folder")
-		_ = os.RemoveAll(path.Join(componentPath, ".terraform"))
+		if !info.DryRun {
+			_ = os.RemoveAll(filepath.Join(componentPath, ".terraform"))
+		}
 
 		fmt.Println("Deleting '.terraform.lock.hcl' file")
-		_ = os.Remove(path.Join(componentPath, ".terraform.lock.hcl"))
+		if !info.DryRun {
+			_ = os.Remove(filepath.Join(componentPath, ".terraform.lock.hcl"))
+		}
 
 		fmt.Println(fmt.Sprintf("Deleting terraform varfile: %s", varFile))
-		_ = os.Remove(path.Join(componentPath, varFile))
+		if !info.DryRun {
+			_ = os.Remove(filepath.Join(componentPath, varFile))
+		}
 
 		fmt.Println(fmt.Sprintf("Deleting terraform planfile: %s", planFile))
-		_ = os.Remove(path.Join(componentPath, planFile))
+		if !info.DryRun {
+			_ = os.Remove(filepath.Join(componentPath, planFile))
+		}
 
 		tfDataDir := os.Getenv("TF_DATA_DIR")
-		if len(tfDataDir) > 0 && tfDataDir != "." && tfDataDir != "/" && tfDataDir != "./" {
+		if len(tfDataDir) > 0 && tfDataDir != "." && tfDataDir != "/" && tfDataDir != "./" && !info.DryRun {
 			color.Cyan("Found ENV var TF_DATA_DIR=%s", tfDataDir)
 			var userAnswer string
 			fmt.Println(fmt.Sprintf("Do you want to delete the folder '%s'? (only 'yes' will be accepted to approve)", tfDataDir))
@@ -111,14 +156,28 @@ func ExecuteTerraform(cmd *cobra.Command, args []string) error {
 	if len(varFileNameFromArg) > 0 {
 		varFilePath = varFileNameFromArg
 	} else {
-		varFilePath = constructTerraformComponentVarfilePath(info)
+		varFilePath = constructTerraformComponentVarfilePath(componentPath, info)
+	}
+
+	// Files generated by atmos in this run. If `--clean-generated` flag is specified, they are deleted after the command completes
+	var generatedFiles []string
+	if info.CleanGenerated {
+		defer func() {
+			removeGeneratedFiles(generatedFiles)
+		}()
 	}
 
 	color.Cyan("Writing the variables to file:")
 	fmt.Println(varFilePath)
-	err = utils.WriteToFileAsJSON(varFilePath, info.ComponentVarsSection, 0644)
-	if err != nil {
-		return err
+	if !info.DryRun {
+		// `terraform varfile` and `terraform write varfile` custom commands are used to generate the varfile, don't delete it
+		if info.CleanGenerated && info.SubCommand != "varfile" && info.SubCommand != "write varfile" {
+			generatedFiles = trackGeneratedFile(generatedFiles, varFilePath)
+		}
+		err = utils.WriteToFileAsJSON(varFilePath, info.ComponentVarsSection, 0644)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Handle `terraform varfile` and `terraform write varfile` custom commands
@@ -129,19 +188,30 @@ func ExecuteTerraform(cmd *cobra.Command, args []string) error {
 
 	// Auto generate backend file
 	if c.Config.Components.Terraform.AutoGenerateBackendFile == true {
-		backendFileName := path.Join(
-			constructTerraformComponentWorkingDir(info),
-			"backend.tf.json",
-		)
+		backendFileName := filepath.Join(componentPath, "backend.tf.json")
 
 		fmt.Println()
 		color.Cyan("Writing the backend config to file:")
 		fmt.Println(backendFileName)
-		var componentBackendConfig = generateComponentBackendConfig(info.ComponentBackendType, info.ComponentBackendSection)
-		err = utils.WriteToFileAsJSON(backendFileName, componentBackendConfig, 0644)
+		componentBackendConfig, err := generateComponentBackendConfig(info.ComponentBackendType, info.ComponentBackendSection)
 		if err != nil {
 			return err
 		}
+		if !info.DryRun {
+			if info.CleanGenerated {
+				generatedFiles = trackGeneratedFile(generatedFiles, backendFileName)
+			}
+			err = utils.WriteToFileAsJSON(backendFileName, componentBackendConfig, 0644)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// `terraform init` can fail transiently when downloading providers and modules, retry it if configured
+	initRetries, initRetryDelay, err := getTerraformInitRetries()
+	if err != nil {
+		return err
 	}
 
 	// Run `terraform init`
@@ -156,7 +226,7 @@ func ExecuteTerraform(cmd *cobra.Command, args []string) error {
 		if info.SubCommand == "workspace" {
 			initCommandWithArguments = []string{"init", "-reconfigure"}
 		}
-		err = execCommand(info.Command, initCommandWithArguments, componentPath, info.ComponentEnvList)
+		_, err = execCommandWithRetries(info.Command, initCommandWithArguments, componentPath, info.ComponentEnvList, getExecOptions(info), initRetries, initRetryDelay)
 		if err != nil {
 			return err
 		}
@@ -194,11 +264,10 @@ func ExecuteTerraform(cmd *cobra.Command, args []string) error {
 		fmt.Println("Stack: " + info.StackFromArg)
 	} else {
 		fmt.Println("Stack: " + info.StackFromArg)
-		fmt.Println("Stack path: " + path.Join(c.Config.BasePath, c.Config.Stacks.BasePath, info.Stack))
+		fmt.Println("Stack path: " + filepath.Join(c.Config.BasePath, c.Config.Stacks.BasePath, info.Stack))
 	}
 
-	workingDir := constructTerraformComponentWorkingDir(info)
-	fmt.Println(fmt.Sprintf(fmt.Sprintf("Working dir: %s", workingDir)))
+	fmt.Println(fmt.Sprintf("Working dir: %s", componentPath))
 
 	// Print ENV vars if they are found in the component's stack config
 	if len(info.ComponentEnvList) > 0 {
@@ -211,24 +280,33 @@ func ExecuteTerraform(cmd *cobra.Command, args []string) error {
 
 	allArgsAndFlags := []string{info.SubCommand}
 
+	// The var files found in the component directory are passed after the generated varfile, so they take precedence
+	varFileArgs := []string{"-var-file", varFile}
+	if c.Config.Components.Terraform.DiscoverVarFiles {
+		for _, f := range discoverTerraformVarFiles(componentPath, info.ContextPrefix) {
+			varFileArgs = append(varFileArgs, []string{"-var-file", f}...)
+		}
+	}
+
 	switch info.SubCommand {
 	case "plan":
-		allArgsAndFlags = append(allArgsAndFlags, []string{"-var-file", varFile, "-out", planFile}...)
+		allArgsAndFlags = append(allArgsAndFlags, varFileArgs...)
+		allArgsAndFlags = append(allArgsAndFlags, []string{"-out", planFile}...)
 		break
 	case "destroy":
-		allArgsAndFlags = append(allArgsAndFlags, []string{"-var-file", varFile}...)
+		allArgsAndFlags = append(allArgsAndFlags, varFileArgs...)
 		break
 	case "import":
-		allArgsAndFlags = append(allArgsAndFlags, []string{"-var-file", varFile}...)
+		allArgsAndFlags = append(allArgsAndFlags, varFileArgs...)
 		break
 	case "refresh":
-		allArgsAndFlags = append(allArgsAndFlags, []string{"-var-file", varFile}...)
+		allArgsAndFlags = append(allArgsAndFlags, varFileArgs...)
 		break
 	case "apply":
 		if info.UseTerraformPlan == true {
 			allArgsAndFlags = append(allArgsAndFlags, []string{planFile}...)
 		} else {
-			allArgsAndFlags = append(allArgsAndFlags, []string{"-var-file", varFile}...)
+			allArgsAndFlags = append(allArgsAndFlags, varFileArgs...)
 		}
 		break
 	}
@@ -236,10 +314,10 @@ func ExecuteTerraform(cmd *cobra.Command, args []string) error {
 	allArgsAndFlags = append(allArgsAndFlags, info.AdditionalArgsAndFlags...)
 
 	// Run `terraform workspace`
-	if info.SubCommand != "init" {
-		err = execCommand(info.Command, []string{"workspace", "select", info.TerraformWorkspace}, componentPath, info.ComponentEnvList)
+	if info.SubCommand != "init" && c.Config.Components.Terraform.UseWorkspaces {
+		_, err = execCommand(info.Command, []string{"workspace", "select", info.TerraformWorkspace}, componentPath, info.ComponentEnvList, getExecOptions(info))
 		if err != nil {
-			err = execCommand(info.Command, []string{"workspace", "new", info.TerraformWorkspace}, componentPath, info.ComponentEnvList)
+			_, err = execCommand(info.Command, []string{"workspace", "new", info.TerraformWorkspace}, componentPath, info.ComponentEnvList, getExecOptions(info))
 			if err != nil {
 				return err
 			}
@@ -271,12 +349,17 @@ func ExecuteTerraform(cmd *cobra.Command, args []string) error {
 
 	// Execute `terraform shell` command
 	if info.SubCommand == "shell" {
+		if info.DryRun {
+			fmt.Println()
+			color.Cyan(fmt.Sprintf("Dry run. Would start a new interactive shell in the working dir: %s\n", componentPath))
+			return nil
+		}
 		err = execTerraformShellCommand(
 			info.ComponentFromArg,
 			info.Stack,
 			info.ComponentEnvList,
 			varFile,
-			workingDir,
+			componentPath,
 			info.TerraformWorkspace,
 			componentPath,
 		)
@@ -287,22 +370,64 @@ func ExecuteTerraform(cmd *cobra.Command, args []string) error {
 	}
 
 	// Execute the provided command
-	if info.SubCommand != "workspace" {
-		err = execCommand(info.Command, allArgsAndFlags, componentPath, info.ComponentEnvList)
+	if info.SubCommand == "init" {
+		_, err = execCommandWithRetries(info.Command, allArgsAndFlags, componentPath, info.ComponentEnvList, getExecOptions(info), initRetries, initRetryDelay)
+		if err != nil {
+			return err
+		}
+	} else if info.SubCommand != "workspace" {
+		result, err := execCommand(info.Command, allArgsAndFlags, componentPath, info.ComponentEnvList, getExecOptions(info))
+		err = processTerraformCommandResult(info.SubCommand, result, err)
 		if err != nil {
 			return err
 		}
 	}
 
 	// Clean up
-	if info.SubCommand != "plan" {
-		planFilePath := constructTerraformComponentPlanfilePath(info)
+	if info.SubCommand != "plan" && !info.DryRun {
+		planFilePath := constructTerraformComponentPlanfilePath(componentPath, info)
 		_ = os.Remove(planFilePath)
 	}
 
 	return nil
 }
 
+// processTerraformCommandResult returns ErrChangesDetected if `terraform plan` exited with the code 2
+// (`terraform plan -detailed-exitcode` succeeded and the plan has changes). Other errors are returned as is
+func processTerraformCommandResult(subCommand string, result ExecResult, err error) error {
+	if err != nil && subCommand == "plan" && result.ExitCode == terraformPlanChangesExitCode {
+		return ErrChangesDetected
+	}
+	return err
+}
+
+// getTerraformInitRetries returns the number of retries and the initial delay between the retries for `terraform init`
+// from the `ATMOS_INIT_RETRIES` (default 0) and `ATMOS_INIT_RETRY_DELAY` (default 5s) ENV vars
+func getTerraformInitRetries() (int, time.Duration, error) {
+	retries := 0
+	delay := 5 * time.Second
+
+	initRetries := os.Getenv("ATMOS_INIT_RETRIES")
+	if len(initRetries) > 0 {
+		r, err := strconv.Atoi(initRetries)
+		if err != nil || r < 0 {
+			return 0, 0, errors.New(fmt.Sprintf("invalid value '%s' of the ENV var ATMOS_INIT_RETRIES. It must be a non-negative integer", initRetries))
+		}
+		retries = r
+	}
+
+	initRetryDelay := os.Getenv("ATMOS_INIT_RETRY_DELAY")
+	if len(initRetryDelay) > 0 {
+		d, err := time.ParseDuration(initRetryDelay)
+		if err != nil || d < 0 {
+			return 0, 0, errors.New(fmt.Sprintf("invalid value '%s' of the ENV var ATMOS_INIT_RETRY_DELAY. It must be a duration (e.g. '5s')", initRetryDelay))
+		}
+		delay = d
+	}
+
+	return retries, delay, nil
+}
+
 func checkTerraformConfig() error {
 	if len(c.Config.Components.Terraform.BasePath) < 1 {
 		return errors.New("Base path to terraform components must be provided in 'components.terraform.base_path' config or " +
@@ -7,7 +7,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
-	"path"
+	"path/filepath"
 )
 
 // ExecuteTerraformGenerateBackend executes `terraform generate backend` command
@@ -43,7 +43,10 @@ func ExecuteTerraformGenerateBackend(cmd *cobra.Command, args []string) error {
 		return errors.New(fmt.Sprintf("\nCould not find 'backend' config for the '%s' component.\n", component))
 	}
 
-	var componentBackendConfig = generateComponentBackendConfig(info.ComponentBackendType, info.ComponentBackendSection)
+	componentBackendConfig, err := generateComponentBackendConfig(info.ComponentBackendType, info.ComponentBackendSection)
+	if err != nil {
+		return err
+	}
 
 	fmt.Println()
 	color.Cyan("Component backend config:\n\n")
@@ -58,13 +61,11 @@ func ExecuteTerraformGenerateBackend(cmd *cobra.Command, args []string) error {
 	}
 
 	// Write backend config to file
-	var backendFilePath = path.Join(
-		c.Config.BasePath,
-		c.Config.Components.Terraform.BasePath,
-		info.ComponentFolderPrefix,
-		info.FinalComponent,
-		"backend.tf.json",
-	)
+	workingDir, err := TerraformWorkingDir(filepath.Join(info.ComponentFolderPrefix, info.FinalComponent))
+	if err != nil {
+		return err
+	}
+	var backendFilePath = filepath.Join(workingDir, "backend.tf.json")
 
 	fmt.Println()
 	color.Cyan("Writing the backend config to file:")
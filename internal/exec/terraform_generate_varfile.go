@@ -7,6 +7,7 @@ import (
 	"github.com/cloudposse/atmos/pkg/utils"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"path/filepath"
 )
 
 // ExecuteTerraformGenerateVarfile executes `terraform generate varfile` command
@@ -45,7 +46,11 @@ func ExecuteTerraformGenerateVarfile(cmd *cobra.Command, args []string) error {
 	if len(varFileNameFromArg) > 0 {
 		varFilePath = varFileNameFromArg
 	} else {
-		varFilePath = constructTerraformComponentVarfilePath(info)
+		workingDir, err := TerraformWorkingDir(filepath.Join(info.ComponentFolderPrefix, info.FinalComponent))
+		if err != nil {
+			return err
+		}
+		varFilePath = constructTerraformComponentVarfilePath(workingDir, info)
 	}
 
 	// Print the component variables
@@ -3,12 +3,18 @@ package exec
 import (
 	"errors"
 	"fmt"
+	"github.com/bmatcuk/doublestar/v4"
 	c "github.com/cloudposse/atmos/pkg/config"
 	g "github.com/cloudposse/atmos/pkg/globals"
 	s "github.com/cloudposse/atmos/pkg/stack"
 	"github.com/cloudposse/atmos/pkg/utils"
 	"github.com/fatih/color"
+	jsoniter "github.com/json-iterator/go"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+	"os"
+	"path"
+	"strconv"
 	"strings"
 )
 
@@ -16,20 +22,69 @@ var (
 	commonFlags = []string{
 		"--stack",
 		"-s",
-		"--dry-run",
 		"--kubeconfig-path",
 		g.TerraformDirFlag,
 		g.HelmfileDirFlag,
 		g.ConfigDirFlag,
 		g.StackDirFlag,
 		g.BasePathFlag,
+		g.StackNamePatternFlag,
+		g.ConfigFlag,
 		g.GlobalOptionsFlag,
 		g.DeployRunInitFlag,
 		g.AutoGenerateBackendFileFlag,
 		g.FromPlanFlag,
+		g.DryRunFlag,
+		g.CleanGeneratedFlag,
+		g.NoSchemaValidationFlag,
+		g.RedirectStdErrFlag,
+		g.RedirectStdOutFlag,
+		g.VarFlag,
+		g.VarsFileFlag,
+		g.LogsLevelFlag,
 		g.HelpFlag1,
 		g.HelpFlag2,
 	}
+
+	// Terraform backend types supported by the backend config generator
+	// (including the backends removed in Terraform 1.3: artifactory, etcd, etcdv3, manta and swift)
+	// https://www.terraform.io/language/settings/backends
+	supportedBackendTypes = []string{
+		"artifactory",
+		"azurerm",
+		"consul",
+		"cos",
+		"etcd",
+		"etcdv3",
+		"gcs",
+		"http",
+		"kubernetes",
+		"local",
+		"manta",
+		"oss",
+		"pg",
+		"remote",
+		"s3",
+		"swift",
+	}
+
+	// Pairs of flags that can't be specified together
+	mutuallyExclusiveFlags = [][2]string{
+		// The planfile is applied as is, `terraform init` is not needed
+		{g.FromPlanFlag, g.DeployRunInitFlag},
+		// The planfile is not created in the dry-run mode
+		{g.DryRunFlag, g.FromPlanFlag},
+		// The variables are already in the planfile
+		{g.FromPlanFlag, g.VarFlag},
+	}
+
+	// Common flags that don't have values
+	commonBoolFlags = []string{
+		g.FromPlanFlag,
+		g.DryRunFlag,
+		g.CleanGeneratedFlag,
+		g.NoSchemaValidationFlag,
+	}
 )
 
 // FindComponentConfig finds component config sections
@@ -168,11 +223,33 @@ func processArgsConfigAndStacks(componentType string, cmd *cobra.Command, args [
 	configAndStacksInfo.StacksDir = argsAndFlagsInfo.StacksDir
 	configAndStacksInfo.ConfigDir = argsAndFlagsInfo.ConfigDir
 	configAndStacksInfo.WorkflowsDir = argsAndFlagsInfo.WorkflowsDir
+	configAndStacksInfo.StackNamePattern = argsAndFlagsInfo.StackNamePattern
+	configAndStacksInfo.ConfigFiles = argsAndFlagsInfo.ConfigFiles
 	configAndStacksInfo.DeployRunInit = argsAndFlagsInfo.DeployRunInit
 	configAndStacksInfo.AutoGenerateBackendFile = argsAndFlagsInfo.AutoGenerateBackendFile
 	configAndStacksInfo.UseTerraformPlan = argsAndFlagsInfo.UseTerraformPlan
+	configAndStacksInfo.DryRun = argsAndFlagsInfo.DryRun
+	configAndStacksInfo.CleanGenerated = argsAndFlagsInfo.CleanGenerated
+	configAndStacksInfo.NoSchemaValidation = argsAndFlagsInfo.NoSchemaValidation
+	configAndStacksInfo.RedirectStdErr = argsAndFlagsInfo.RedirectStdErr
+	configAndStacksInfo.RedirectStdOut = argsAndFlagsInfo.RedirectStdOut
+	configAndStacksInfo.Vars = argsAndFlagsInfo.Vars
+	configAndStacksInfo.VarsFile = argsAndFlagsInfo.VarsFile
+	configAndStacksInfo.LogsLevel = argsAndFlagsInfo.LogsLevel
 	configAndStacksInfo.NeedHelp = argsAndFlagsInfo.NeedHelp
 
+	// `ATMOS_DRY_RUN` ENV var enables the dry-run mode in addition to the `--dry-run` command-line flag
+	dryRun := os.Getenv("ATMOS_DRY_RUN")
+	if len(dryRun) > 0 {
+		dryRunBool, err := strconv.ParseBool(dryRun)
+		if err != nil {
+			return configAndStacksInfo, errors.New(fmt.Sprintf("invalid value '%s' of the ENV var ATMOS_DRY_RUN. It must be a boolean (e.g. 'true')", dryRun))
+		}
+		if dryRunBool {
+			configAndStacksInfo.DryRun = true
+		}
+	}
+
 	// Check if `-h` or `--help` flags are specified
 	if argsAndFlagsInfo.NeedHelp == true {
 		err = processHelp(componentType, argsAndFlagsInfo.SubCommand)
@@ -209,7 +286,7 @@ func ProcessStacks(configAndStacksInfo c.ConfigAndStacksInfo) (c.ConfigAndStacks
 	configAndStacksInfo.StackFromArg = configAndStacksInfo.Stack
 
 	// Process and merge CLI configurations
-	err := c.InitConfig()
+	err := c.InitConfigWithArgs(configAndStacksInfo)
 	if err != nil {
 		return configAndStacksInfo, err
 	}
@@ -219,12 +296,16 @@ func ProcessStacks(configAndStacksInfo c.ConfigAndStacksInfo) (c.ConfigAndStacks
 		return configAndStacksInfo, err
 	}
 
+	// Resolve the stack alias (if the provided stack is an alias)
+	configAndStacksInfo.Stack = c.ResolveStackAlias(configAndStacksInfo.Stack)
+
 	// Process stack config file(s)
-	_, stacksMap, err := s.ProcessYAMLConfigFiles(
+	_, stacksMap, err := s.ProcessYAMLConfigFilesWithOptions(
 		c.ProcessedConfig.StacksBaseAbsolutePath,
 		c.ProcessedConfig.StackConfigFilesAbsolutePaths,
 		false,
-		true)
+		true,
+		getStackProcessOptions(configAndStacksInfo.IncludeSourceMetadata))
 
 	if err != nil {
 		return configAndStacksInfo, err
@@ -246,15 +327,22 @@ func ProcessStacks(configAndStacksInfo c.ConfigAndStacksInfo) (c.ConfigAndStacks
 		}
 	}
 
-	if len(c.Config.Stacks.NamePattern) < 1 {
+	if len(c.Config.Stacks.NamePattern) < 1 && c.ProcessedConfig.StackType != "None" {
 		return configAndStacksInfo,
 			errors.New("stack name pattern must be provided in 'stacks.name_pattern' config or 'ATMOS_STACKS_NAME_PATTERN' ENV variable")
 	}
 
-	stackNamePatternParts := strings.Split(c.Config.Stacks.NamePattern, "-")
-
 	// Check and process stacks
-	if c.ProcessedConfig.StackType == "Directory" {
+	if c.ProcessedConfig.StackType == "None" {
+		// No stack config files (`stacks.require_stacks: false`), the variables of the component are provided in the `--vars-file` file
+		if len(configAndStacksInfo.VarsFile) == 0 {
+			return configAndStacksInfo,
+				errors.New(fmt.Sprintf("no stack config files found. The '%s' flag must be provided to target the component '%s' without stacks",
+					g.VarsFileFlag, configAndStacksInfo.ComponentFromArg))
+		}
+		configAndStacksInfo.ComponentSection = map[string]interface{}{}
+		configAndStacksInfo.ComponentVarsSection = map[interface{}]interface{}{}
+	} else if c.ProcessedConfig.StackType == "Directory" {
 		configAndStacksInfo.ComponentSection,
 			configAndStacksInfo.ComponentVarsSection,
 			configAndStacksInfo.ComponentEnvSection,
@@ -272,36 +360,30 @@ func ProcessStacks(configAndStacksInfo c.ConfigAndStacksInfo) (c.ConfigAndStacks
 
 		configAndStacksInfo.ComponentEnvList = convertEnvVars(configAndStacksInfo.ComponentEnvSection)
 	} else {
-		if g.LogVerbose {
-			color.Cyan("Searching for stack config where the component '%s' is defined\n", configAndStacksInfo.ComponentFromArg)
-		}
+		// Check if the provided stack exists
+		stackNames := getStackNamesFromStacksMap(stacksMap)
 
-		stackParts := strings.Split(configAndStacksInfo.Stack, "-")
-		if len(stackParts) != len(stackNamePatternParts) {
-			return configAndStacksInfo,
-				errors.New(fmt.Sprintf("Stack '%s' does not match the stack name pattern '%s'",
-					configAndStacksInfo.Stack,
-					c.Config.Stacks.NamePattern))
+		// Check that all stack aliases resolve to existing stacks and don't hide the existing stacks.
+		// In strict mode, the invalid aliases are an error
+		err = c.CheckStackAliases(stackNames)
+		if err != nil {
+			if c.Config.Settings.StrictMode {
+				return configAndStacksInfo, err
+			}
+			color.Yellow("Warning: %v\n", err)
 		}
 
-		var tenant string
-		var environment string
-		var stage string
-		var tenantFound bool
-		var environmentFound bool
-		var stageFound bool
+		if !utils.SliceContainsString(stackNames, configAndStacksInfo.Stack) {
+			return configAndStacksInfo, errors.New(stackNotFoundMessage(configAndStacksInfo.Stack, stackNames))
+		}
 
-		for i, part := range stackNamePatternParts {
-			if part == "{tenant}" {
-				tenant = stackParts[i]
-			} else if part == "{environment}" {
-				environment = stackParts[i]
-			} else if part == "{stage}" {
-				stage = stackParts[i]
-			}
+		if g.LogVerbose {
+			color.Cyan("Searching for stack config where the component '%s' is defined\n", configAndStacksInfo.ComponentFromArg)
 		}
 
-		for stackName := range stacksMap {
+		componentFound := false
+
+		for stackFileName := range stacksMap {
 			configAndStacksInfo.ComponentSection,
 				configAndStacksInfo.ComponentVarsSection,
 				configAndStacksInfo.ComponentEnvSection,
@@ -312,48 +394,27 @@ func ProcessStacks(configAndStacksInfo c.ConfigAndStacksInfo) (c.ConfigAndStacks
 				configAndStacksInfo.ComponentInheritanceChain,
 				configAndStacksInfo.ComponentIsAbstract,
 				configAndStacksInfo.ComponentMetadataSection,
-				err = FindComponentConfig(stackName, stacksMap, configAndStacksInfo.ComponentType, configAndStacksInfo.ComponentFromArg)
+				err = FindComponentConfig(stackFileName, stacksMap, configAndStacksInfo.ComponentType, configAndStacksInfo.ComponentFromArg)
 			if err != nil {
 				continue
 			}
 
-			configAndStacksInfo.ComponentEnvList = convertEnvVars(configAndStacksInfo.ComponentEnvSection)
-
-			tenantFound = true
-			environmentFound = true
-			stageFound = true
-
-			// Search for tenant in stack
-			if len(tenant) > 0 {
-				if tenantInStack, ok := configAndStacksInfo.ComponentVarsSection["tenant"].(string); !ok || tenantInStack != tenant {
-					tenantFound = false
-				}
-			}
-
-			// Search for environment in stack
-			if len(environment) > 0 {
-				if environmentInStack, ok := configAndStacksInfo.ComponentVarsSection["environment"].(string); !ok || environmentInStack != environment {
-					environmentFound = false
-				}
+			// Check if the component is in the provided logical stack (the logical stack name is calculated by `StackNameFunc`)
+			if stackName, ok := getComponentStackName(stackFileName, configAndStacksInfo.ComponentSection); !ok || stackName != configAndStacksInfo.Stack {
+				continue
 			}
 
-			// Search for stage in stack
-			if len(stage) > 0 {
-				if stageInStack, ok := configAndStacksInfo.ComponentVarsSection["stage"].(string); !ok || stageInStack != stage {
-					stageFound = false
-				}
-			}
+			configAndStacksInfo.ComponentEnvList = convertEnvVars(configAndStacksInfo.ComponentEnvSection)
 
-			if tenantFound == true && environmentFound == true && stageFound == true {
-				if g.LogVerbose {
-					color.Green("Found stack config for the component '%s' in the stack '%s'\n\n", configAndStacksInfo.ComponentFromArg, stackName)
-				}
-				configAndStacksInfo.Stack = stackName
-				break
+			if g.LogVerbose {
+				color.Green("Found stack config for the component '%s' in the stack '%s'\n\n", configAndStacksInfo.ComponentFromArg, stackFileName)
 			}
+			configAndStacksInfo.Stack = stackFileName
+			componentFound = true
+			break
 		}
 
-		if tenantFound == false || environmentFound == false || stageFound == false {
+		if !componentFound {
 			return configAndStacksInfo,
 				errors.New(fmt.Sprintf("\nCould not find config for the component '%s' in the stack '%s'.\n"+
 					"Check that all attributes in the stack name pattern '%s' are defined in the stack config files.\n"+
@@ -365,8 +426,17 @@ func ProcessStacks(configAndStacksInfo c.ConfigAndStacksInfo) (c.ConfigAndStacks
 		}
 	}
 
+	// The command from the CLI config is the default for the components that don't specify a custom binary to execute,
+	// it's applied by the stack processor (see getStackProcessOptions).
+	// Without stacks, the stack processor does not run, and the command is taken from the CLI config here
 	if len(configAndStacksInfo.Command) == 0 {
-		configAndStacksInfo.Command = configAndStacksInfo.ComponentType
+		if configAndStacksInfo.ComponentType == "terraform" && len(c.Config.Components.Terraform.Command) > 0 {
+			configAndStacksInfo.Command = c.Config.Components.Terraform.Command
+		} else if configAndStacksInfo.ComponentType == "helmfile" && len(c.Config.Components.Helmfile.Command) > 0 {
+			configAndStacksInfo.Command = c.Config.Components.Helmfile.Command
+		} else {
+			configAndStacksInfo.Command = configAndStacksInfo.ComponentType
+		}
 	}
 
 	// Process component path and name
@@ -404,9 +474,14 @@ func ProcessStacks(configAndStacksInfo c.ConfigAndStacksInfo) (c.ConfigAndStacks
 
 	// Process context
 	configAndStacksInfo.Context = c.GetContextFromVars(configAndStacksInfo.ComponentVarsSection)
-	configAndStacksInfo.ContextPrefix, err = c.GetContextPrefix(configAndStacksInfo.Stack, configAndStacksInfo.Context, c.Config.Stacks.NamePattern)
-	if err != nil {
-		return configAndStacksInfo, err
+	if c.ProcessedConfig.StackType == "None" {
+		// Without stacks, the provided stack name is used as is
+		configAndStacksInfo.ContextPrefix = configAndStacksInfo.Stack
+	} else {
+		configAndStacksInfo.ContextPrefix, err = c.StackNameFunc(configAndStacksInfo.Stack, configAndStacksInfo.ComponentSection)
+		if err != nil {
+			return configAndStacksInfo, err
+		}
 	}
 
 	// workspace
@@ -420,13 +495,120 @@ func ProcessStacks(configAndStacksInfo c.ConfigAndStacksInfo) (c.ConfigAndStacks
 		workspace = fmt.Sprintf("%s-%s", configAndStacksInfo.ContextPrefix, configAndStacksInfo.ComponentFromArg)
 	}
 
-	workspace = strings.Replace(workspace, "/", "-", -1)
+	// Terraform workspaces are not used, all the stacks use the `default` workspace
+	if !c.Config.Components.Terraform.UseWorkspaces {
+		workspace = terraformDefaultWorkspace
+	}
+
+	workspace = utils.SanitizeTerraformWorkspaceName(workspace)
 	configAndStacksInfo.TerraformWorkspace = workspace
 	configAndStacksInfo.ComponentSection["workspace"] = workspace
 
+	// The variables from the `--vars-file` file and then from the `--var` command-line flags override the variables from the stack config.
+	// They are applied after the context and workspace are calculated, so they don't change the stack name and the Terraform workspace
+	if len(configAndStacksInfo.VarsFile) > 0 || len(configAndStacksInfo.Vars) > 0 {
+		// The component sections are shared with the other components in the stacks map, override the copies of them
+		componentVarsSection := map[interface{}]interface{}{}
+		for k, v := range configAndStacksInfo.ComponentVarsSection {
+			componentVarsSection[k] = v
+		}
+
+		if len(configAndStacksInfo.VarsFile) > 0 {
+			varsFromFile, err := readVarsFile(configAndStacksInfo.VarsFile)
+			if err != nil {
+				return configAndStacksInfo, err
+			}
+			for k, v := range varsFromFile {
+				componentVarsSection[k] = v
+			}
+		}
+
+		if len(configAndStacksInfo.Vars) > 0 {
+			varOverrides, err := parseVarOverrides(configAndStacksInfo.Vars)
+			if err != nil {
+				return configAndStacksInfo, err
+			}
+			for k, v := range varOverrides {
+				componentVarsSection[k] = v
+			}
+		}
+
+		componentSection := map[string]interface{}{}
+		for k, v := range configAndStacksInfo.ComponentSection {
+			componentSection[k] = v
+		}
+		componentSection["vars"] = componentVarsSection
+
+		configAndStacksInfo.ComponentVarsSection = componentVarsSection
+		configAndStacksInfo.ComponentSection = componentSection
+	}
+
 	return configAndStacksInfo, nil
 }
 
+// readVarsFile reads the variables of the component from the YAML or JSON file specified in the `--vars-file` command-line flag
+func readVarsFile(varsFile string) (map[interface{}]interface{}, error) {
+	if !utils.FileExists(varsFile) {
+		return nil, errors.New(fmt.Sprintf("the file '%s' specified with the '%s' flag does not exist", varsFile, g.VarsFileFlag))
+	}
+
+	content, err := os.ReadFile(varsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := map[interface{}]interface{}{}
+	err = yaml.Unmarshal(content, &vars)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("invalid file '%s' specified with the '%s' flag: %v", varsFile, g.VarsFileFlag, err))
+	}
+
+	return vars, nil
+}
+
+// parseVarOverrides parses the `key=value` variables from the `--var` command-line flags.
+// The values are parsed as JSON (e.g. numbers, booleans, lists and maps). If a value is not a valid JSON, it's used as a string
+func parseVarOverrides(vars []string) (map[interface{}]interface{}, error) {
+	res := map[interface{}]interface{}{}
+
+	for _, v := range vars {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			return nil, errors.New(fmt.Sprintf("invalid flag '%s %s'. The variable must be specified as 'key=value'", g.VarFlag, v))
+		}
+
+		var value interface{}
+		err := jsoniter.ConfigCompatibleWithStandardLibrary.UnmarshalFromString(parts[1], &value)
+		if err != nil {
+			value = parts[1]
+		}
+
+		res[parts[0]] = value
+	}
+
+	return res, nil
+}
+
+// checkMutuallyExclusiveFlags returns an error if the provided arguments/flags contain flags that can't be specified together
+func checkMutuallyExclusiveFlags(inputArgsAndFlags []string) error {
+	flagSpecified := func(flag string) bool {
+		for _, arg := range inputArgsAndFlags {
+			if arg == flag || strings.HasPrefix(arg, flag+"=") {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, flags := range mutuallyExclusiveFlags {
+		if flagSpecified(flags[0]) && flagSpecified(flags[1]) {
+			return errors.New(fmt.Sprintf("the flags '%s' and '%s' can't be specified together", flags[0], flags[1]))
+		}
+	}
+
+	return nil
+}
+
 // processArgsAndFlags removes common args and flags from the provided list of arguments/flags
 func processArgsAndFlags(inputArgsAndFlags []string) (c.ArgsAndFlagsInfo, error) {
 	var info c.ArgsAndFlagsInfo
@@ -438,6 +620,11 @@ func processArgsAndFlags(inputArgsAndFlags []string) (c.ArgsAndFlagsInfo, error)
 	// https://github.com/roboll/helmfile#cli-reference
 	var globalOptionsFlagIndex int
 
+	err := checkMutuallyExclusiveFlags(inputArgsAndFlags)
+	if err != nil {
+		return info, err
+	}
+
 	for i, arg := range inputArgsAndFlags {
 		if arg == g.GlobalOptionsFlag {
 			globalOptionsFlagIndex = i + 1
@@ -510,6 +697,93 @@ func processArgsAndFlags(inputArgsAndFlags []string) (c.ArgsAndFlagsInfo, error)
 			info.BasePath = stacksDirFlagParts[1]
 		}
 
+		if arg == g.StackNamePatternFlag {
+			if len(inputArgsAndFlags) <= (i + 1) {
+				return info, errors.New(fmt.Sprintf("invalid flag: %s", arg))
+			}
+			info.StackNamePattern = inputArgsAndFlags[i+1]
+		} else if strings.HasPrefix(arg, g.StackNamePatternFlag+"=") {
+			var stackNamePatternFlagParts = strings.Split(arg, "=")
+			if len(stackNamePatternFlagParts) != 2 {
+				return info, errors.New(fmt.Sprintf("invalid flag: %s", arg))
+			}
+			info.StackNamePattern = stackNamePatternFlagParts[1]
+		}
+
+		if arg == g.RedirectStdErrFlag {
+			if len(inputArgsAndFlags) <= (i + 1) {
+				return info, errors.New(fmt.Sprintf("invalid flag: %s", arg))
+			}
+			info.RedirectStdErr = inputArgsAndFlags[i+1]
+		} else if strings.HasPrefix(arg, g.RedirectStdErrFlag+"=") {
+			var redirectStdErrFlagParts = strings.Split(arg, "=")
+			if len(redirectStdErrFlagParts) != 2 {
+				return info, errors.New(fmt.Sprintf("invalid flag: %s", arg))
+			}
+			info.RedirectStdErr = redirectStdErrFlagParts[1]
+		}
+
+		if arg == g.RedirectStdOutFlag {
+			if len(inputArgsAndFlags) <= (i + 1) {
+				return info, errors.New(fmt.Sprintf("invalid flag: %s", arg))
+			}
+			info.RedirectStdOut = inputArgsAndFlags[i+1]
+		} else if strings.HasPrefix(arg, g.RedirectStdOutFlag+"=") {
+			var redirectStdOutFlagParts = strings.Split(arg, "=")
+			if len(redirectStdOutFlagParts) != 2 {
+				return info, errors.New(fmt.Sprintf("invalid flag: %s", arg))
+			}
+			info.RedirectStdOut = redirectStdOutFlagParts[1]
+		}
+
+		if arg == g.VarsFileFlag {
+			if len(inputArgsAndFlags) <= (i + 1) {
+				return info, errors.New(fmt.Sprintf("invalid flag: %s", arg))
+			}
+			info.VarsFile = inputArgsAndFlags[i+1]
+		} else if strings.HasPrefix(arg, g.VarsFileFlag+"=") {
+			var varsFileFlagParts = strings.Split(arg, "=")
+			if len(varsFileFlagParts) != 2 {
+				return info, errors.New(fmt.Sprintf("invalid flag: %s", arg))
+			}
+			info.VarsFile = varsFileFlagParts[1]
+		}
+
+		if arg == g.LogsLevelFlag {
+			if len(inputArgsAndFlags) <= (i + 1) {
+				return info, errors.New(fmt.Sprintf("invalid flag: %s", arg))
+			}
+			info.LogsLevel = inputArgsAndFlags[i+1]
+		} else if strings.HasPrefix(arg, g.LogsLevelFlag+"=") {
+			var logsLevelFlagParts = strings.Split(arg, "=")
+			if len(logsLevelFlagParts) != 2 {
+				return info, errors.New(fmt.Sprintf("invalid flag: %s", arg))
+			}
+			info.LogsLevel = logsLevelFlagParts[1]
+		}
+
+		if arg == g.VarFlag {
+			if len(inputArgsAndFlags) <= (i + 1) {
+				return info, errors.New(fmt.Sprintf("invalid flag: %s", arg))
+			}
+			info.Vars = append(info.Vars, inputArgsAndFlags[i+1])
+		} else if strings.HasPrefix(arg, g.VarFlag+"=") {
+			info.Vars = append(info.Vars, strings.TrimPrefix(arg, g.VarFlag+"="))
+		}
+
+		if arg == g.ConfigFlag {
+			if len(inputArgsAndFlags) <= (i + 1) {
+				return info, errors.New(fmt.Sprintf("invalid flag: %s", arg))
+			}
+			info.ConfigFiles = append(info.ConfigFiles, inputArgsAndFlags[i+1])
+		} else if strings.HasPrefix(arg, g.ConfigFlag+"=") {
+			var configFlagParts = strings.Split(arg, "=")
+			if len(configFlagParts) != 2 {
+				return info, errors.New(fmt.Sprintf("invalid flag: %s", arg))
+			}
+			info.ConfigFiles = append(info.ConfigFiles, configFlagParts[1])
+		}
+
 		if arg == g.DeployRunInitFlag {
 			if len(inputArgsAndFlags) <= (i + 1) {
 				return info, errors.New(fmt.Sprintf("invalid flag: %s", arg))
@@ -553,6 +827,18 @@ func processArgsAndFlags(inputArgsAndFlags []string) (c.ArgsAndFlagsInfo, error)
 			info.UseTerraformPlan = true
 		}
 
+		if arg == g.DryRunFlag {
+			info.DryRun = true
+		}
+
+		if arg == g.CleanGeneratedFlag {
+			info.CleanGenerated = true
+		}
+
+		if arg == g.NoSchemaValidationFlag {
+			info.NoSchemaValidation = true
+		}
+
 		if arg == g.HelpFlag1 || arg == g.HelpFlag2 {
 			info.NeedHelp = true
 		}
@@ -560,7 +846,9 @@ func processArgsAndFlags(inputArgsAndFlags []string) (c.ArgsAndFlagsInfo, error)
 		for _, f := range commonFlags {
 			if arg == f {
 				indexesToRemove = append(indexesToRemove, i)
-				indexesToRemove = append(indexesToRemove, i+1)
+				if !utils.SliceContainsString(commonBoolFlags, f) {
+					indexesToRemove = append(indexesToRemove, i+1)
+				}
 			} else if strings.HasPrefix(arg, f+"=") {
 				indexesToRemove = append(indexesToRemove, i)
 			}
@@ -610,13 +898,83 @@ func processArgsAndFlags(inputArgsAndFlags []string) (c.ArgsAndFlagsInfo, error)
 	return info, nil
 }
 
-func generateComponentBackendConfig(backendType string, backendConfig map[interface{}]interface{}) map[string]interface{} {
+// generateComponentBackendConfig generates the terraform backend config for the component from the backend type
+// and the backend config for the type. It returns an error if the backend type is not supported
+func generateComponentBackendConfig(backendType string, backendConfig map[interface{}]interface{}) (map[string]interface{}, error) {
+	if !utils.SliceContainsString(supportedBackendTypes, backendType) {
+		return nil, errors.New(fmt.Sprintf("unsupported backend type '%s'. Supported backend types: %s",
+			backendType,
+			strings.Join(supportedBackendTypes, ", "),
+		))
+	}
+
+	if backendConfig == nil {
+		backendConfig = map[interface{}]interface{}{}
+	}
+
 	return map[string]interface{}{
 		"terraform": map[string]interface{}{
 			"backend": map[string]interface{}{
 				backendType: backendConfig,
 			},
 		},
+	}, nil
+}
+
+// checkComponentAllowed checks the component against the glob patterns in `components.allowlist` and `components.denylist`.
+// The component is not permitted if it matches any pattern in the denylist, or if the allowlist is not empty and the component
+// does not match any pattern in the allowlist. The denylist takes precedence over the allowlist
+func checkComponentAllowed(component string) error {
+	for _, pattern := range c.Config.Components.Denylist {
+		match, err := doublestar.Match(pattern, component)
+		if err != nil {
+			return err
+		}
+		if match {
+			return errors.New(fmt.Sprintf("component '%s' is not permitted in this configuration", component))
+		}
+	}
+
+	if len(c.Config.Components.Allowlist) == 0 {
+		return nil
+	}
+
+	for _, pattern := range c.Config.Components.Allowlist {
+		match, err := doublestar.Match(pattern, component)
+		if err != nil {
+			return err
+		}
+		if match {
+			return nil
+		}
+	}
+
+	return errors.New(fmt.Sprintf("component '%s' is not permitted in this configuration", component))
+}
+
+// trackGeneratedFile adds the file to the list of files generated by atmos in the current invocation.
+// If the file already exists (e.g. it was created by hand or by a previous run), it's not tracked and will not be deleted
+func trackGeneratedFile(generatedFiles []string, filePath string) []string {
+	if utils.FileExists(filePath) {
+		return generatedFiles
+	}
+	return append(generatedFiles, filePath)
+}
+
+// removeGeneratedFiles removes the files generated by atmos in the current invocation
+func removeGeneratedFiles(generatedFiles []string) {
+	if len(generatedFiles) == 0 {
+		return
+	}
+
+	fmt.Println()
+	color.Cyan("Removing the generated files:")
+	for _, f := range generatedFiles {
+		fmt.Println(f)
+		err := os.Remove(f)
+		if err != nil && !os.IsNotExist(err) {
+			color.Yellow("Error deleting the generated file '%s': %s\n", f, err)
+		}
 	}
 }
 
@@ -630,3 +988,23 @@ func convertEnvVars(envVarsMap map[interface{}]interface{}) []string {
 	}
 	return res
 }
+
+// checkComponentNotAbstract checks that the component is not provisioned by one of the `provisionSubCommands`
+// if it's marked as not deployable (`metadata.type: abstract`). Abstract components can only be inherited from,
+// but the other subcommands (e.g. `plan`) and the `describe` commands work for them
+func checkComponentNotAbstract(info c.ConfigAndStacksInfo, provisionSubCommands []string) error {
+	if !info.ComponentIsAbstract || !utils.SliceContainsString(provisionSubCommands, info.SubCommand) {
+		return nil
+	}
+	return errors.New(fmt.Sprintf("Abstract component '%s' cannot be provisioned since it's explicitly prohibited from being deployed "+
+		"by 'metadata.type: abstract' attribute", path.Join(info.ComponentFolderPrefix, info.Component)))
+}
+
+// getExecOptions returns the options of the executed commands from the command-line flags
+func getExecOptions(info c.ConfigAndStacksInfo) ExecOptions {
+	return ExecOptions{
+		DryRun:         info.DryRun,
+		RedirectStdErr: info.RedirectStdErr,
+		RedirectStdOut: info.RedirectStdOut,
+	}
+}
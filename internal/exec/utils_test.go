@@ -0,0 +1,431 @@
+package exec
+
+import (
+	c "github.com/cloudposse/atmos/pkg/config"
+	u "github.com/cloudposse/atmos/pkg/utils"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func setComponentsAllowlistAndDenylist(t *testing.T, allowlist []string, denylist []string) {
+	components := c.Config.Components
+	t.Cleanup(func() {
+		c.Config.Components = components
+	})
+
+	c.Config.Components.Allowlist = allowlist
+	c.Config.Components.Denylist = denylist
+}
+
+func TestCheckComponentAllowedWithoutLists(t *testing.T) {
+	setComponentsAllowlistAndDenylist(t, nil, nil)
+
+	assert.Nil(t, checkComponentAllowed("infra/vpc"))
+	assert.Nil(t, checkComponentAllowed("test/test-component"))
+}
+
+func TestCheckComponentAllowedWithAllowlist(t *testing.T) {
+	setComponentsAllowlistAndDenylist(t, []string{"infra/*", "echo-server"}, nil)
+
+	assert.Nil(t, checkComponentAllowed("infra/vpc"))
+	assert.Nil(t, checkComponentAllowed("echo-server"))
+
+	err := checkComponentAllowed("test/test-component")
+	assert.NotNil(t, err)
+	assert.Equal(t, "component 'test/test-component' is not permitted in this configuration", err.Error())
+}
+
+func TestCheckComponentAllowedWithDenylist(t *testing.T) {
+	setComponentsAllowlistAndDenylist(t, nil, []string{"test/**"})
+
+	assert.Nil(t, checkComponentAllowed("infra/vpc"))
+
+	err := checkComponentAllowed("test/test-component")
+	assert.NotNil(t, err)
+	assert.Equal(t, "component 'test/test-component' is not permitted in this configuration", err.Error())
+}
+
+func TestCheckComponentAllowedDenylistTakesPrecedence(t *testing.T) {
+	setComponentsAllowlistAndDenylist(t, []string{"infra/*"}, []string{"infra/vpc"})
+
+	assert.Nil(t, checkComponentAllowed("infra/infra-server"))
+
+	err := checkComponentAllowed("infra/vpc")
+	assert.NotNil(t, err)
+	assert.Equal(t, "component 'infra/vpc' is not permitted in this configuration", err.Error())
+}
+
+func TestGenerateComponentBackendConfig(t *testing.T) {
+	testCases := []struct {
+		backendType   string
+		backendConfig map[interface{}]interface{}
+	}{
+		{
+			backendType: "s3",
+			backendConfig: map[interface{}]interface{}{
+				"bucket":               "eg-ue2-root-tfstate",
+				"key":                  "terraform.tfstate",
+				"region":               "us-east-2",
+				"workspace_key_prefix": "infra-vpc",
+			},
+		},
+		{
+			backendType: "gcs",
+			backendConfig: map[interface{}]interface{}{
+				"bucket": "eg-tfstate",
+				"prefix": "infra-vpc",
+			},
+		},
+		{
+			backendType: "azurerm",
+			backendConfig: map[interface{}]interface{}{
+				"resource_group_name":  "rg-terraform-state",
+				"storage_account_name": "staterraformstate",
+				"container_name":       "dev-tfstate",
+				"key":                  "dev.atmos/infra-vpc.terraform.tfstate",
+			},
+		},
+		{
+			backendType:   "http",
+			backendConfig: map[interface{}]interface{}{"address": "https://tfstate.example.com/infra-vpc"},
+		},
+		{
+			backendType:   "consul",
+			backendConfig: map[interface{}]interface{}{"address": "consul.example.com", "path": "tfstate/infra-vpc"},
+		},
+		{
+			backendType:   "pg",
+			backendConfig: map[interface{}]interface{}{"conn_str": "postgres://localhost/tfstate", "schema_name": "infra_vpc"},
+		},
+		{
+			backendType:   "kubernetes",
+			backendConfig: map[interface{}]interface{}{"secret_suffix": "infra-vpc", "config_path": "~/.kube/config"},
+		},
+		{
+			backendType:   "etcdv3",
+			backendConfig: map[interface{}]interface{}{"endpoints": []interface{}{"etcd-1:2379"}, "prefix": "tfstate/"},
+		},
+		{
+			backendType:   "local",
+			backendConfig: map[interface{}]interface{}{"path": "terraform.tfstate"},
+		},
+	}
+
+	for _, tc := range testCases {
+		backendConfig, err := generateComponentBackendConfig(tc.backendType, tc.backendConfig)
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"terraform": map[string]interface{}{
+				"backend": map[string]interface{}{
+					tc.backendType: tc.backendConfig,
+				},
+			},
+		}, backendConfig)
+	}
+
+	// The backend config is empty if not provided
+	backendConfig, err := generateComponentBackendConfig("local", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"terraform": map[string]interface{}{
+			"backend": map[string]interface{}{
+				"local": map[interface{}]interface{}{},
+			},
+		},
+	}, backendConfig)
+}
+
+func TestGenerateComponentBackendConfigWithUnsupportedBackendType(t *testing.T) {
+	_, err := generateComponentBackendConfig("vault", map[interface{}]interface{}{})
+	assert.NotNil(t, err)
+	assert.Equal(t, "unsupported backend type 'vault'. Supported backend types: "+
+		"artifactory, azurerm, consul, cos, etcd, etcdv3, gcs, http, kubernetes, local, manta, oss, pg, remote, s3, swift", err.Error())
+}
+
+func TestProcessArgsAndFlagsWithVars(t *testing.T) {
+	info, err := processArgsAndFlags([]string{"plan", "vpc", "--var", "name=test", "--var=count=3", "-refresh=false"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"name=test", "count=3"}, info.Vars)
+	assert.Equal(t, "plan", info.SubCommand)
+	assert.Equal(t, "vpc", info.ComponentFromArg)
+	// The `--var` flags are not passed to the executed command
+	assert.Equal(t, []string{"-refresh=false"}, info.AdditionalArgsAndFlags)
+}
+
+func TestProcessArgsAndFlagsWithLogsLevel(t *testing.T) {
+	info, err := processArgsAndFlags([]string{"plan", "vpc", "--logs-level", "debug"})
+	assert.Nil(t, err)
+	assert.Equal(t, "debug", info.LogsLevel)
+	assert.Empty(t, info.AdditionalArgsAndFlags)
+
+	info, err = processArgsAndFlags([]string{"plan", "vpc", "--logs-level=warn"})
+	assert.Nil(t, err)
+	assert.Equal(t, "warn", info.LogsLevel)
+}
+
+func TestProcessArgsAndFlagsWithStackNamePattern(t *testing.T) {
+	info, err := processArgsAndFlags([]string{"plan", "vpc", "--stack-name-pattern", "{tenant}-{stage}", "-refresh=false"})
+	assert.Nil(t, err)
+	assert.Equal(t, "{tenant}-{stage}", info.StackNamePattern)
+	// The flag is not passed to the executed command
+	assert.Equal(t, []string{"-refresh=false"}, info.AdditionalArgsAndFlags)
+
+	info, err = processArgsAndFlags([]string{"plan", "vpc", "--stack-name-pattern={stage}"})
+	assert.Nil(t, err)
+	assert.Equal(t, "{stage}", info.StackNamePattern)
+
+	_, err = processArgsAndFlags([]string{"plan", "vpc", "--stack-name-pattern"})
+	assert.NotNil(t, err)
+}
+
+func TestProcessArgsAndFlagsWithFromPlan(t *testing.T) {
+	info, err := processArgsAndFlags([]string{"apply", "vpc", "--from-plan", "-lock=false"})
+	assert.Nil(t, err)
+	assert.True(t, info.UseTerraformPlan)
+	// `--from-plan` does not have a value, the next argument is passed to the executed command
+	assert.Equal(t, []string{"-lock=false"}, info.AdditionalArgsAndFlags)
+}
+
+func TestProcessArgsAndFlagsWithRedirects(t *testing.T) {
+	info, err := processArgsAndFlags([]string{"plan", "vpc", "--redirect-stdout", "stdout.log", "--redirect-stderr=stderr.log", "-refresh=false"})
+	assert.Nil(t, err)
+	assert.Equal(t, "stdout.log", info.RedirectStdOut)
+	assert.Equal(t, "stderr.log", info.RedirectStdErr)
+	// The redirect flags are not passed to the executed command
+	assert.Equal(t, []string{"-refresh=false"}, info.AdditionalArgsAndFlags)
+}
+
+func TestParseVarOverrides(t *testing.T) {
+	vars, err := parseVarOverrides([]string{
+		"name=test-vpc",
+		"cidr=10.0.0.0/16",
+		"count=3",
+		"enabled=false",
+		`tags={"team":"eks","cost_center":42}`,
+		"empty=",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "test-vpc", vars["name"])
+	// Not a valid JSON, used as a string
+	assert.Equal(t, "10.0.0.0/16", vars["cidr"])
+	assert.Equal(t, float64(3), vars["count"])
+	assert.Equal(t, false, vars["enabled"])
+	assert.Equal(t, map[string]interface{}{"team": "eks", "cost_center": float64(42)}, vars["tags"])
+	assert.Equal(t, "", vars["empty"])
+
+	_, err = parseVarOverrides([]string{"enabled"})
+	assert.NotNil(t, err)
+}
+
+func TestProcessArgsAndFlagsWithMutuallyExclusiveFlags(t *testing.T) {
+	_, err := processArgsAndFlags([]string{"deploy", "vpc", "--from-plan", "--deploy-run-init", "true"})
+	assert.NotNil(t, err)
+	assert.Equal(t, "the flags '--from-plan' and '--deploy-run-init' can't be specified together", err.Error())
+
+	_, err = processArgsAndFlags([]string{"apply", "vpc", "--dry-run", "--from-plan"})
+	assert.NotNil(t, err)
+	assert.Equal(t, "the flags '--dry-run' and '--from-plan' can't be specified together", err.Error())
+
+	_, err = processArgsAndFlags([]string{"apply", "vpc", "--from-plan", "--var=name=test"})
+	assert.NotNil(t, err)
+	assert.Equal(t, "the flags '--from-plan' and '--var' can't be specified together", err.Error())
+
+	// Each flag can be specified without the others
+	_, err = processArgsAndFlags([]string{"deploy", "vpc", "--deploy-run-init=true", "--dry-run"})
+	assert.Nil(t, err)
+	_, err = processArgsAndFlags([]string{"apply", "vpc", "--from-plan"})
+	assert.Nil(t, err)
+}
+
+func TestProcessStacksWithoutStacks(t *testing.T) {
+	dir := setupTestStacks(t, `
+stacks:
+  base_path: "stacks"
+  included_paths:
+    - "**/*"
+  require_stacks: false
+`, map[string]string{})
+	varsFile := filepath.Join(dir, "vpc.dev.yaml")
+	assert.Nil(t, os.WriteFile(varsFile, []byte("cidr_block: 10.0.0.0/16\nenabled: true\n"), 0644))
+
+	info := c.ConfigAndStacksInfo{ComponentType: "terraform", ComponentFromArg: "vpc", Stack: "dev"}
+
+	// The vars file is required without stacks
+	_, err := ProcessStacks(info)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "'--vars-file' flag must be provided")
+
+	info.VarsFile = varsFile
+	info.Vars = []string{"enabled=false"}
+	info, err = ProcessStacks(info)
+	assert.Nil(t, err)
+	assert.Equal(t, map[interface{}]interface{}{"cidr_block": "10.0.0.0/16", "enabled": false}, info.ComponentVarsSection)
+	assert.Equal(t, "dev", info.ContextPrefix)
+	assert.Equal(t, "vpc", info.FinalComponent)
+}
+
+func TestAbstractComponentCanBeDescribedButNotProvisioned(t *testing.T) {
+	setupTestStacks(t, `
+stacks:
+  base_path: "stacks"
+  included_paths:
+    - "**/*"
+  name_pattern: "{stage}"
+`, map[string]string{
+		"dev.yaml": `
+vars:
+  stage: dev
+components:
+  terraform:
+    vpc-defaults:
+      metadata:
+        type: abstract
+        component: vpc
+      vars:
+        cidr_block: 10.0.0.0/16
+    vpc:
+      component: vpc-defaults
+      vars:
+        enabled: true
+`,
+	})
+
+	info, err := ProcessStacks(c.ConfigAndStacksInfo{ComponentType: "terraform", ComponentFromArg: "vpc-defaults", Stack: "dev"})
+	assert.Nil(t, err)
+	assert.True(t, info.ComponentIsAbstract)
+
+	info.SubCommand = "apply"
+	err = checkComponentNotAbstract(info, terraformProvisionSubCommands)
+	assert.NotNil(t, err)
+	assert.Equal(t, "Abstract component 'vpc-defaults' cannot be provisioned since it's explicitly prohibited from being deployed "+
+		"by 'metadata.type: abstract' attribute", err.Error())
+
+	info.SubCommand = "plan"
+	assert.Nil(t, checkComponentNotAbstract(info, terraformProvisionSubCommands))
+
+	// The components that inherit from the abstract component can be provisioned
+	info, err = ProcessStacks(c.ConfigAndStacksInfo{ComponentType: "terraform", ComponentFromArg: "vpc", Stack: "dev", SubCommand: "apply"})
+	assert.Nil(t, err)
+	assert.False(t, info.ComponentIsAbstract)
+	assert.Nil(t, checkComponentNotAbstract(info, terraformProvisionSubCommands))
+
+	componentSection, err := DescribeComponent("vpc-defaults", "dev")
+	assert.Nil(t, err)
+	assert.Equal(t, map[interface{}]interface{}{"type": "abstract", "component": "vpc"}, componentSection["metadata"])
+}
+
+func TestProcessArgsAndFlagsWithCleanGenerated(t *testing.T) {
+	info, err := processArgsAndFlags([]string{"plan", "vpc", "--clean-generated", "-refresh=false"})
+	assert.Nil(t, err)
+	assert.True(t, info.CleanGenerated)
+	assert.Equal(t, []string{"-refresh=false"}, info.AdditionalArgsAndFlags)
+}
+
+func TestTrackAndRemoveGeneratedFiles(t *testing.T) {
+	dir := t.TempDir()
+	existingFile := filepath.Join(dir, "backend.tf.json")
+	assert.Nil(t, os.WriteFile(existingFile, []byte("{}"), 0644))
+	generatedFile := filepath.Join(dir, "dev-vpc.terraform.tfvars.json")
+
+	// Only the files that don't exist before they are written are tracked
+	var generatedFiles []string
+	generatedFiles = trackGeneratedFile(generatedFiles, existingFile)
+	generatedFiles = trackGeneratedFile(generatedFiles, generatedFile)
+	assert.Equal(t, []string{generatedFile}, generatedFiles)
+
+	assert.Nil(t, os.WriteFile(generatedFile, []byte("{}"), 0644))
+	removeGeneratedFiles(generatedFiles)
+
+	assert.False(t, u.FileExists(generatedFile))
+	assert.True(t, u.FileExists(existingFile))
+
+	// Removing the files that were already deleted is not an error
+	removeGeneratedFiles(generatedFiles)
+}
+
+func TestProcessStacksComponentCommandTakesPrecedence(t *testing.T) {
+	setupTestStacks(t, `
+components:
+  terraform:
+    command: "/usr/local/bin/terraform-1.1"
+stacks:
+  base_path: "stacks"
+  included_paths:
+    - "**/*"
+  name_pattern: "{stage}"
+`, map[string]string{
+		"dev.yaml": `
+vars:
+  stage: dev
+components:
+  terraform:
+    vpc:
+      command: terraform
+      vars: {}
+    eks:
+      vars: {}
+`,
+	})
+
+	// The command specified in the component overrides the command from the CLI config
+	info, err := ProcessStacks(c.ConfigAndStacksInfo{ComponentType: "terraform", ComponentFromArg: "vpc", Stack: "dev"})
+	assert.Nil(t, err)
+	assert.Equal(t, "terraform", info.Command)
+
+	// The command from the CLI config is used when the component does not specify a command
+	info, err = ProcessStacks(c.ConfigAndStacksInfo{ComponentType: "terraform", ComponentFromArg: "eks", Stack: "dev"})
+	assert.Nil(t, err)
+	assert.Equal(t, "/usr/local/bin/terraform-1.1", info.Command)
+
+	// Without stacks, the command from the CLI config is used
+	dir := setupTestStacks(t, `
+components:
+  terraform:
+    command: "/usr/local/bin/terraform-1.1"
+stacks:
+  base_path: "stacks"
+  included_paths:
+    - "**/*"
+  require_stacks: false
+`, map[string]string{})
+	varsFile := filepath.Join(dir, "vpc.dev.yaml")
+	assert.Nil(t, os.WriteFile(varsFile, []byte("enabled: true\n"), 0644))
+
+	info, err = ProcessStacks(c.ConfigAndStacksInfo{ComponentType: "terraform", ComponentFromArg: "vpc", Stack: "dev", VarsFile: varsFile})
+	assert.Nil(t, err)
+	assert.Equal(t, "/usr/local/bin/terraform-1.1", info.Command)
+}
+
+func TestProcessStacksVarOverridesDontChangeStackName(t *testing.T) {
+	dir := setupTestStacks(t, testAtmosConfig, testStackConfigs)
+	varsFile := filepath.Join(dir, "vpc.yaml")
+	assert.Nil(t, os.WriteFile(varsFile, []byte("stage: prod\ncidr_block: 10.1.0.0/16\n"), 0644))
+
+	info, err := ProcessStacks(c.ConfigAndStacksInfo{
+		ComponentType:    "terraform",
+		ComponentFromArg: "vpc",
+		Stack:            "tenant1-dev",
+		VarsFile:         varsFile,
+		Vars:             []string{"tenant=tenant2"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "prod", info.ComponentVarsSection["stage"])
+	assert.Equal(t, "tenant2", info.ComponentVarsSection["tenant"])
+	assert.Equal(t, "10.1.0.0/16", info.ComponentVarsSection["cidr_block"])
+	assert.Equal(t, info.ComponentVarsSection, info.ComponentSection["vars"])
+
+	// The stack name and the workspace are calculated from the vars in the stack config
+	assert.Equal(t, "tenant1-dev", info.ContextPrefix)
+	assert.Equal(t, "tenant1-dev", info.TerraformWorkspace)
+}
+
+func TestProcessArgsConfigAndStacksWithInvalidDryRunEnvVar(t *testing.T) {
+	t.Setenv("ATMOS_DRY_RUN", "maybe")
+
+	_, err := processArgsConfigAndStacks("terraform", &cobra.Command{}, []string{"plan", "vpc"})
+	assert.NotNil(t, err)
+	assert.Equal(t, "invalid value 'maybe' of the ENV var ATMOS_DRY_RUN. It must be a boolean (e.g. 'true')", err.Error())
+}
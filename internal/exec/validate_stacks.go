@@ -0,0 +1,51 @@
+package exec
+
+import (
+	"fmt"
+	c "github.com/cloudposse/atmos/pkg/config"
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sort"
+	"strings"
+)
+
+// ExecuteValidateStacks executes `validate stacks` command
+func ExecuteValidateStacks(cmd *cobra.Command, args []string) error {
+	// Check that the stack names don't collide with the component directory names (an error in strict mode)
+	collisions, err := FindStackAndComponentNameCollisions()
+	if err != nil {
+		return err
+	}
+
+	if len(collisions) > 0 {
+		if c.Config.Settings.StrictMode {
+			return errors.New(strings.Join(collisions, "\n"))
+		}
+		for _, collision := range collisions {
+			color.Yellow("Warning: %s", collision)
+		}
+	}
+
+	orphanedStacks, err := FindOrphanedStacks()
+	if err != nil {
+		return err
+	}
+
+	if len(orphanedStacks) > 0 {
+		var stackFiles []string
+		for stackFile := range orphanedStacks {
+			stackFiles = append(stackFiles, stackFile)
+		}
+		sort.Strings(stackFiles)
+		for _, stackFile := range stackFiles {
+			color.Yellow("%s: %s", stackFile, orphanedStacks[stackFile])
+		}
+
+		return errors.New(fmt.Sprintf("found %d stack config file(s) that don't produce valid stack names using the stack name pattern",
+			len(orphanedStacks)))
+	}
+
+	color.Cyan("All stack config files produce valid stack names")
+	return nil
+}
@@ -8,9 +8,6 @@ import (
 	u "github.com/cloudposse/atmos/pkg/utils"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v2"
-	"io/ioutil"
-	"path"
 	"path/filepath"
 )
 
@@ -36,7 +33,7 @@ func ExecuteWorkflow(cmd *cobra.Command, args []string) error {
 	if u.IsPathAbsolute(workflowFile) {
 		workflowPath = workflowFile
 	} else {
-		workflowPath = path.Join(c.Config.BasePath, c.Config.Workflows.BasePath, workflowFile)
+		workflowPath = filepath.Join(c.Config.BasePath, c.Config.Workflows.BasePath, workflowFile)
 	}
 
 	// If the file is specified without an extension, use the default extension
@@ -50,25 +47,13 @@ func ExecuteWorkflow(cmd *cobra.Command, args []string) error {
 		return errors.New(fmt.Sprintf("File '%s' does not exist", workflowPath))
 	}
 
-	fileContent, err := ioutil.ReadFile(workflowPath)
+	workflowConfig, err := readWorkflowConfig(workflowPath)
 	if err != nil {
 		return err
 	}
 
-	var yamlContent c.WorkflowFile
-	var workflowConfig c.WorkflowConfig
 	var workflowDefinition c.WorkflowDefinition
 
-	if err = yaml.Unmarshal(fileContent, &yamlContent); err != nil {
-		return err
-	}
-
-	if i, ok := yamlContent["workflows"]; !ok {
-		return errors.New("a workflow file must be a map with top-level 'workflows:' key")
-	} else {
-		workflowConfig = i
-	}
-
 	workflow := args[0]
 
 	if i, ok := workflowConfig[workflow]; !ok {
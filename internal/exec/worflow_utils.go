@@ -3,11 +3,183 @@ package exec
 import (
 	"errors"
 	"fmt"
+	"github.com/bmatcuk/doublestar/v4"
 	c "github.com/cloudposse/atmos/pkg/config"
+	u "github.com/cloudposse/atmos/pkg/utils"
 	"github.com/fatih/color"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 )
 
+// readWorkflowConfig reads the workflows defined in the workflow file
+func readWorkflowConfig(workflowPath string) (c.WorkflowConfig, error) {
+	fileContent, err := ioutil.ReadFile(workflowPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var yamlContent c.WorkflowFile
+	if err = yaml.Unmarshal(fileContent, &yamlContent); err != nil {
+		return nil, err
+	}
+
+	workflowConfig, ok := yamlContent["workflows"]
+	if !ok {
+		return nil, errors.New("a workflow file must be a map with top-level 'workflows:' key")
+	}
+
+	return workflowConfig, nil
+}
+
+// findWorkflowConfigFiles returns the sorted paths to all the workflow files in `workflows.base_path` (including the subdirectories)
+func findWorkflowConfigFiles() ([]string, error) {
+	workflowsDir := filepath.Join(c.Config.BasePath, c.Config.Workflows.BasePath)
+	if _, err := os.Stat(workflowsDir); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	var res []string
+	err := filepath.Walk(workflowsDir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && u.IsYaml(p) {
+			res = append(res, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(res)
+	return res, nil
+}
+
+// findAllWorkflows returns a map of the workflow names to the workflow files where the workflows are defined.
+// The workflow names must be unique across all the workflow files
+func findAllWorkflows() (map[string]string, error) {
+	workflowConfigFiles, err := findWorkflowConfigFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[string]string{}
+
+	for _, workflowPath := range workflowConfigFiles {
+		workflowConfig, err := readWorkflowConfig(workflowPath)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("invalid workflow file '%s': %v", workflowPath, err))
+		}
+
+		for workflow := range workflowConfig {
+			if existingWorkflowPath, ok := res[workflow]; ok {
+				return nil, errors.New(fmt.Sprintf("the workflow '%s' is defined in both '%s' and '%s'", workflow, existingWorkflowPath, workflowPath))
+			}
+			res[workflow] = workflowPath
+		}
+	}
+
+	return res, nil
+}
+
+// ListWorkflows returns the sorted names of all the workflows defined in the workflow files in `workflows.base_path`
+func ListWorkflows() ([]string, error) {
+	err := c.InitConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.ApplyEnvVars()
+	if err != nil {
+		return nil, err
+	}
+
+	workflows, err := findAllWorkflows()
+	if err != nil {
+		return nil, err
+	}
+
+	return sortedWorkflowNames(workflows), nil
+}
+
+// sortedWorkflowNames returns the sorted names of the workflows
+func sortedWorkflowNames(workflows map[string]string) []string {
+	var res []string
+	for workflow := range workflows {
+		res = append(res, workflow)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// RunAllWorkflows executes all the workflows with the names matching the glob filter (all the workflows if the filter is empty) in sorted order.
+// If a workflow fails, the next workflows are still executed, and all the failures are returned at the end.
+// If `workflows.fail_fast` is `true`, the execution stops at the first failed workflow
+func RunAllWorkflows(filter string) error {
+	err := c.InitConfig()
+	if err != nil {
+		return err
+	}
+
+	err = c.ApplyEnvVars()
+	if err != nil {
+		return err
+	}
+
+	workflows, err := findAllWorkflows()
+	if err != nil {
+		return err
+	}
+
+	// The atmos commands in the workflow steps initialize the CLI config again
+	failFast := c.Config.Workflows.FailFast
+
+	var failures []string
+	executed := 0
+
+	for _, workflow := range sortedWorkflowNames(workflows) {
+		if len(filter) > 0 {
+			match, err := doublestar.Match(filter, workflow)
+			if err != nil {
+				return errors.New(fmt.Sprintf("invalid workflow filter '%s': %v", filter, err))
+			}
+			if !match {
+				continue
+			}
+		}
+
+		workflowPath := workflows[workflow]
+		workflowConfig, err := readWorkflowConfig(workflowPath)
+		if err != nil {
+			return err
+		}
+
+		color.Cyan("\nExecuting the workflow '%s' from '%s'\n", workflow, workflowPath)
+		fmt.Println()
+		executed++
+
+		err = executeWorkflowSteps(workflowConfig[workflow])
+		if err != nil {
+			if failFast {
+				return errors.New(fmt.Sprintf("the workflow '%s' from '%s' failed: %v", workflow, workflowPath, err))
+			}
+			color.Red("The workflow '%s' from '%s' failed: %v\n", workflow, workflowPath, err)
+			failures = append(failures, fmt.Sprintf("'%s' (%s): %v", workflow, workflowPath, err))
+		}
+	}
+
+	if len(failures) > 0 {
+		return errors.New(fmt.Sprintf("%d of %d workflows failed:\n%s", len(failures), executed, strings.Join(failures, "\n")))
+	}
+
+	return nil
+}
+
 func executeWorkflowSteps(workflowDefinition c.WorkflowDefinition) error {
 	var steps = workflowDefinition.Steps
 
@@ -23,7 +195,7 @@ func executeWorkflowSteps(workflowDefinition c.WorkflowDefinition) error {
 
 		if commandType == "shell" {
 			args := strings.Fields(command)
-			if err := execCommand(args[0], args[1:], ".", []string{}); err != nil {
+			if _, err := execCommand(args[0], args[1:], ".", []string{}, ExecOptions{}); err != nil {
 				return err
 			}
 		} else if commandType == "atmos" {
@@ -47,7 +219,7 @@ func executeWorkflowSteps(workflowDefinition c.WorkflowDefinition) error {
 				color.HiCyan(fmt.Sprintf("Stack: %s", finalStack))
 			}
 
-			if err := execCommand("atmos", args, ".", []string{}); err != nil {
+			if _, err := execCommand("atmos", args, ".", []string{}, ExecOptions{}); err != nil {
 				return err
 			}
 		} else {
@@ -0,0 +1,115 @@
+package exec
+
+import (
+	c "github.com/cloudposse/atmos/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func setupWorkflowsDir(t *testing.T) string {
+	config := c.Config
+	cwd, err := os.Getwd()
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_ = os.Chdir(cwd)
+		c.Config = config
+	})
+
+	dir := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "workflows", "nightly"), 0755))
+	assert.Nil(t, os.Chdir(dir))
+	t.Setenv("HOME", dir)
+	return dir
+}
+
+func TestListWorkflows(t *testing.T) {
+	dir := setupWorkflowsDir(t)
+
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "workflows", "deploy.yaml"), []byte(`
+workflows:
+  deploy-all:
+    steps:
+      - command: terraform deploy vpc
+  deploy-eks:
+    steps:
+      - command: terraform deploy eks
+`), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "workflows", "nightly", "drift.yml"), []byte(`
+workflows:
+  drift-detection:
+    steps:
+      - command: terraform plan vpc
+`), 0644))
+
+	workflows, err := ListWorkflows()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"deploy-all", "deploy-eks", "drift-detection"}, workflows)
+
+	// The workflow names must be unique
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "workflows", "nightly", "deploy.yaml"), []byte(`
+workflows:
+  deploy-all:
+    steps: []
+`), 0644))
+
+	_, err = ListWorkflows()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "the workflow 'deploy-all' is defined in both")
+}
+
+func TestRunAllWorkflows(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the workflow steps are shell scripts")
+	}
+
+	dir := setupWorkflowsDir(t)
+
+	for name, exitCode := range map[string]string{"ok.sh": "0", "fail.sh": "1"} {
+		script := "#!/bin/sh\necho $0 >> executed\nexit " + exitCode + "\n"
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, name), []byte(script), 0755))
+	}
+
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "workflows", "nightly.yaml"), []byte(`
+workflows:
+  nightly-a:
+    steps:
+      - command: `+filepath.Join(dir, "fail.sh")+`
+        type: shell
+  nightly-b:
+    steps:
+      - command: `+filepath.Join(dir, "ok.sh")+`
+        type: shell
+  release:
+    steps:
+      - command: `+filepath.Join(dir, "ok.sh")+`
+        type: shell
+`), 0644))
+
+	readExecuted := func() string {
+		executed, err := os.ReadFile(filepath.Join(dir, "executed"))
+		assert.Nil(t, err)
+		assert.Nil(t, os.Remove(filepath.Join(dir, "executed")))
+		return string(executed)
+	}
+
+	// The failures are reported at the end
+	err := RunAllWorkflows("nightly-*")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "1 of 2 workflows failed")
+	assert.Contains(t, err.Error(), "'nightly-a'")
+	assert.Equal(t, filepath.Join(dir, "fail.sh")+"\n"+filepath.Join(dir, "ok.sh")+"\n", readExecuted())
+
+	err = RunAllWorkflows("release")
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(dir, "ok.sh")+"\n", readExecuted())
+
+	// In fail-fast mode, the execution stops at the first failed workflow
+	t.Setenv("ATMOS_WORKFLOWS_FAIL_FAST", "true")
+	err = RunAllWorkflows("")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "the workflow 'nightly-a'")
+	assert.Equal(t, filepath.Join(dir, "fail.sh")+"\n", readExecuted())
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"fmt"
+	"github.com/bmatcuk/doublestar/v4"
+	u "github.com/cloudposse/atmos/pkg/utils"
+	"github.com/fatih/color"
+	"os"
+	"path/filepath"
+)
+
+// starterConfig is the minimal CLI config written by Bootstrap
+const starterConfig = `# CLI config
+# See https://github.com/cloudposse/atmos for all the supported settings
+
+base_path: ""
+
+components:
+  terraform:
+    base_path: "components/terraform"
+    apply_auto_approve: false
+    deploy_run_init: true
+    auto_generate_backend_file: false
+  helmfile:
+    base_path: "components/helmfile"
+    kubeconfig_path: "/dev/shm"
+    helm_aws_profile_pattern: "{namespace}-{tenant}-gbl-{stage}-helm"
+    cluster_name_pattern: "{namespace}-{tenant}-{environment}-{stage}-eks-cluster"
+
+stacks:
+  base_path: "stacks"
+  included_paths:
+    - "**/*"
+  excluded_paths:
+    - "globals/**/*"
+    - "catalog/**/*"
+    - "**/*globals*"
+  name_pattern: "{tenant}-{environment}-{stage}"
+
+workflows:
+  base_path: "workflows"
+
+logs:
+  verbose: false
+  colors: true
+`
+
+// Bootstrap creates the stacks base directories (the non-glob prefixes of 'stacks.included_paths'),
+// the terraform components directory and the workflows directory if they don't exist,
+// and writes a starter CLI config to the current directory if the CLI config file does not exist there.
+// Existing files and directories are never changed
+func Bootstrap() error {
+	err := InitConfig()
+	if err != nil {
+		return err
+	}
+	err = ApplyEnvVars()
+	if err != nil {
+		return err
+	}
+	normalizeConfigPaths()
+
+	stacksBasePath := filepath.Join(Config.BasePath, Config.Stacks.BasePath)
+	dirs := []string{stacksBasePath}
+	for _, p := range Config.Stacks.IncludedPaths {
+		base, _ := doublestar.SplitPattern(p)
+		dirs = append(dirs, filepath.Join(stacksBasePath, base))
+	}
+	dirs = append(dirs,
+		filepath.Join(Config.BasePath, Config.Components.Terraform.BasePath),
+		filepath.Join(Config.BasePath, Config.Workflows.BasePath),
+	)
+
+	for _, dir := range u.UniqueStrings(dirs) {
+		exists, err := u.IsDirectory(dir)
+		if err == nil && exists {
+			continue
+		}
+		err = os.MkdirAll(dir, 0755)
+		if err != nil {
+			return err
+		}
+		color.Cyan("Created directory %s", dir)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	configFile := filepath.Join(cwd, GetConfigFileName())
+	if u.FileExists(configFile) {
+		return nil
+	}
+
+	// `O_EXCL` guarantees that an existing file is never overwritten
+	f, err := os.OpenFile(configFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprint(f, starterConfig)
+	if err != nil {
+		return err
+	}
+	color.Cyan("Created CLI config %s", configFile)
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBootstrap(t *testing.T) {
+	dir := t.TempDir()
+
+	cwd, err := os.Getwd()
+	assert.Nil(t, err)
+	err = os.Chdir(dir)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_ = os.Chdir(cwd)
+	})
+
+	t.Setenv("ATMOS_STACKS_INCLUDED_PATHS", "orgs/**/*,prod.yaml")
+
+	err = Bootstrap()
+	assert.Nil(t, err)
+
+	for _, d := range []string{"stacks", "stacks/orgs", "components/terraform", "workflows"} {
+		assert.DirExists(t, filepath.Join(dir, d))
+	}
+	assert.FileExists(t, filepath.Join(dir, "atmos.yaml"))
+
+	// The starter CLI config is valid
+	err = InitConfig()
+	assert.Nil(t, err)
+	assert.Equal(t, "{tenant}-{environment}-{stage}", Config.Stacks.NamePattern)
+
+	// Existing files are not overwritten
+	writeTestConfigFile(t, dir, "atmos.yaml", "base_path: \"\"\n")
+	writeTestConfigFile(t, dir, "stacks/orgs/dev.yaml", "vars: {}\n")
+
+	err = Bootstrap()
+	assert.Nil(t, err)
+
+	content, err := os.ReadFile(filepath.Join(dir, "atmos.yaml"))
+	assert.Nil(t, err)
+	assert.Equal(t, "base_path: \"\"\n", string(content))
+
+	content, err = os.ReadFile(filepath.Join(dir, "stacks/orgs/dev.yaml"))
+	assert.Nil(t, err)
+	assert.Equal(t, "vars: {}\n", string(content))
+}
@@ -5,17 +5,20 @@ import (
 	"encoding/json"
 	"fmt"
 	g "github.com/cloudposse/atmos/pkg/globals"
+	l "github.com/cloudposse/atmos/pkg/logger"
+	m "github.com/cloudposse/atmos/pkg/merge"
 	u "github.com/cloudposse/atmos/pkg/utils"
 	"github.com/fatih/color"
 	"github.com/mitchellh/go-homedir"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v2"
+	"io"
 	"os"
-	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 )
 
 var (
@@ -28,12 +31,16 @@ var (
 				ApplyAutoApprove:        false,
 				DeployRunInit:           true,
 				AutoGenerateBackendFile: false,
+				Command:                 "terraform",
+				UseWorkspaces:           true,
+				DiscoverVarFiles:        false,
 			},
 			Helmfile: Helmfile{
 				BasePath:              "components/helmfile",
 				KubeconfigPath:        "/dev/shm",
 				HelmAwsProfilePattern: "{namespace}-{tenant}-gbl-{stage}-helm",
 				ClusterNamePattern:    "{namespace}-{tenant}-{environment}-{stage}-eks-cluster",
+				Command:               "helmfile",
 			},
 		},
 		Stacks: Stacks{
@@ -46,14 +53,28 @@ var (
 				"catalog/**/*",
 				"**/*globals*",
 			},
+			RequireStacks: true,
 		},
 		Workflows: Workflows{
 			BasePath: "workflows",
+			FailFast: false,
 		},
 		Logs: Logs{
 			Verbose: false,
 			Colors:  true,
 		},
+		Settings: Settings{
+			StrictMode:        false,
+			ListMergeStrategy: m.ListMergeStrategyReplace,
+		},
+	}
+
+	// stackNamePatternTokens are the tokens supported in the stack name pattern
+	stackNamePatternTokens = []string{
+		"{namespace}",
+		"{tenant}",
+		"{environment}",
+		"{stage}",
 	}
 
 	// Config is the CLI configuration structure
@@ -61,30 +82,61 @@ var (
 
 	// ProcessedConfig holds all the calculated values
 	ProcessedConfig ProcessedConfiguration
+
+	// ensureConfigOnce guards the CLI config initialization in EnsureConfig
+	ensureConfigOnce   sync.Once
+	ensureConfigResult Configuration
+	ensureConfigErr    error
 )
 
-// InitConfig finds and merges CLI configurations in the following order: system dir, home dir, current dir, ENV vars, command-line arguments
+// EnsureConfig initializes the CLI config (by calling InitConfig and ApplyEnvVars) at most once and returns the cached CLI config (or the cached error)
+// on subsequent calls. It's safe to call EnsureConfig from multiple goroutines
+func EnsureConfig() (Configuration, error) {
+	ensureConfigOnce.Do(func() {
+		ensureConfigErr = InitConfig()
+		if ensureConfigErr == nil {
+			ensureConfigErr = ApplyEnvVars()
+		}
+		ensureConfigResult = Config
+	})
+	return ensureConfigResult, ensureConfigErr
+}
+
+// ResetConfigForTesting clears the CLI config cached by EnsureConfig, so the next call to EnsureConfig initializes the config again.
+// It's intended to be used in tests and must not be called concurrently with EnsureConfig
+func ResetConfigForTesting() {
+	ensureConfigOnce = sync.Once{}
+	ensureConfigResult = Configuration{}
+	ensureConfigErr = nil
+}
+
+// InitConfig finds and merges CLI configurations in the following order: system dir, home dir, current dir, ENV vars
 // https://dev.to/techschoolguru/load-config-from-file-environment-variables-in-golang-with-viper-2j2d
 // https://medium.com/@bnprashanth256/reading-configuration-files-and-environment-variables-in-go-golang-c2607f912b63
 func InitConfig() error {
+	return InitConfigWithArgs(ConfigAndStacksInfo{})
+}
+
+// InitConfigWithArgs finds and merges CLI configurations like InitConfig, and uses the options from the command-line arguments:
+// the config files from the `--config` flags are merged after the config in the current dir,
+// `--logs-level` sets the logs level, and `--no-schema-validation` disables the JSON Schema validation
+func InitConfigWithArgs(configAndStacksInfo ConfigAndStacksInfo) error {
 	// Config is loaded from the following locations (from lower to higher priority):
 	// system dir (`/usr/local/etc/atmos` on Linux, `%LOCALAPPDATA%/atmos` on Windows)
 	// home dir (~/.atmos)
+	// remote config from the URL specified in `ATMOS_REMOTE_CONFIG_URL` ENV var
 	// current directory
+	// config files specified with the `--config` command-line flag (in the order given)
 	// ENV vars
 	// Command-line arguments
+	// The order of the system dir, home dir, current dir (with the remote config and `--config` files) and ENV vars
+	// can be changed with `ATMOS_CONFIG_PRECEDENCE` ENV var
 
-	err := processLogsConfig()
+	err := processLogsConfig(configAndStacksInfo.LogsLevel)
 	if err != nil {
 		return err
 	}
 
-	if g.LogVerbose {
-		color.Cyan("\nProcessing and merging configurations in the following order:\n")
-		fmt.Println("system dir, home dir, current dir, ENV vars, command-line arguments")
-		fmt.Println()
-	}
-
 	v := viper.New()
 	v.SetConfigType("yaml")
 	v.SetTypeByDefaultValue(true)
@@ -100,66 +152,131 @@ func InitConfig() error {
 		return err
 	}
 
-	// Process config in system folder
-	configFilePath1 := ""
+	// The CLI config file name (`atmos.yaml` by default) can be overridden by `ATMOS_CONFIG_FILE_NAME` ENV var
+	configFileName := GetConfigFileName()
 
-	// https://pureinfotech.com/list-environment-variables-windows-10/
-	// https://docs.microsoft.com/en-us/windows/deployment/usmt/usmt-recognized-environment-variables
-	// https://softwareengineering.stackexchange.com/questions/299869/where-is-the-appropriate-place-to-put-application-configuration-files-for-each-p
-	// https://stackoverflow.com/questions/37946282/why-does-appdata-in-windows-7-seemingly-points-to-wrong-folder
-	if runtime.GOOS == "windows" {
-		appDataDir := os.Getenv(g.WindowsAppDataEnvVar)
-		if len(appDataDir) > 0 {
-			configFilePath1 = appDataDir
-		}
-	} else {
-		configFilePath1 = g.SystemDirConfigFilePath
+	// The order of the config sources can be overridden by `ATMOS_CONFIG_PRECEDENCE` ENV var
+	precedence, err := getConfigPrecedence()
+	if err != nil {
+		return err
 	}
 
-	if len(configFilePath1) > 0 {
-		configFile1 := path.Join(configFilePath1, g.ConfigFileName)
-		err = processConfigFile(configFile1, v)
+	l.Debug("\nProcessing and merging configurations in the following order:\n")
+	l.Debug("%s, command-line arguments (the current dir includes the remote config and the '%s' files)\n",
+		strings.Join(precedence, ", "), g.ConfigFlag)
+
+	var remoteConfigErr error
+	var appliedEnvOverrides map[string]string
+
+	for i, source := range precedence {
+		switch source {
+		case configSourceSystem:
+			err = processSystemDirConfig(configFileName, v)
+		case configSourceHome:
+			err = processHomeDirConfig(configFileName, v)
+		case configSourceCwd:
+			// The remote config is merged before the config in the current dir.
+			// If it can't be fetched, it's skipped (an error in strict mode, which is checked after all the sources are merged)
+			remoteConfigURL := getRemoteConfigURL()
+			if len(remoteConfigURL) > 0 {
+				remoteConfigErr = processRemoteConfig(remoteConfigURL, v)
+			}
+			err = processCurrentDirConfigs(configFileName, configAndStacksInfo.ConfigFiles, v)
+		case configSourceEnv:
+			// If the ENV vars have the highest priority (the default), they are applied by ApplyEnvVars
+			if i == len(precedence)-1 {
+				continue
+			}
+			// Otherwise, apply the ENV vars to the config merged so far, and merge the result back, so the next sources override them
+			Config = Configuration{}
+			err = v.Unmarshal(&Config)
+			if err != nil {
+				return err
+			}
+			appliedEnvOverrides, err = processEnvVars()
+			if err != nil {
+				return err
+			}
+			j, err = json.Marshal(Config)
+			if err != nil {
+				return err
+			}
+			err = v.MergeConfig(bytes.NewReader(j))
+		}
 		if err != nil {
 			return err
 		}
 	}
 
-	// Process config in user's HOME dir
-	configFilePath2, err := homedir.Dir()
-	if err != nil {
-		return err
+	if remoteConfigErr != nil {
+		if isStrictModeEnabled(v) {
+			return remoteConfigErr
+		}
+		l.Warn("%v. Skipping the remote config", remoteConfigErr)
 	}
-	configFile2 := path.Join(configFilePath2, ".atmos", g.ConfigFileName)
-	err = processConfigFile(configFile2, v)
+
+	// Check the required atmos version before validating the config, so an old atmos binary fails with a clear error
+	// instead of failing on the config keys it does not support
+	err = checkRequiredVersion(v.GetString("required_version"), AtmosVersion)
 	if err != nil {
 		return err
 	}
 
-	// Process config in the current dir
-	configFilePath3, err := os.Getwd()
+	// https://gist.github.com/chazcheadle/45bf85b793dea2b71bd05ebaa3c28644
+	// https://sagikazarmark.hu/blog/decoding-custom-formats-with-viper/
+	err = v.Unmarshal(&Config)
 	if err != nil {
 		return err
 	}
-	configFile3 := path.Join(configFilePath3, g.ConfigFileName)
-	err = processConfigFile(configFile3, v)
-	if err != nil {
-		return err
+
+	// The colors in the log messages can be disabled with `logs.colors: false`
+	l.SetColors(Config.Logs.Colors)
+
+	// Validate the merged config against the JSON Schema
+	if !configAndStacksInfo.NoSchemaValidation {
+		err = validateConfigWithSchema(v.AllSettings())
+		if err != nil {
+			return err
+		}
 	}
 
-	// https://gist.github.com/chazcheadle/45bf85b793dea2b71bd05ebaa3c28644
-	// https://sagikazarmark.hu/blog/decoding-custom-formats-with-viper/
-	err = v.Unmarshal(&Config)
+	// Record the ENV var overrides if the ENV vars were merged before the other config sources.
+	// Otherwise, the ENV vars are applied by ApplyEnvVars (called from ProcessConfig and ProcessConfigForSpacelift)
+	Config.AppliedEnvOverrides = appliedEnvOverrides
+
+	return nil
+}
+
+// ApplyEnvVars applies the ENV vars to the CLI config and records the applied overrides in `AppliedEnvOverrides`.
+// If `ATMOS_CONFIG_PRECEDENCE` ENV var puts the ENV vars before other config sources, they are already applied by InitConfig
+func ApplyEnvVars() error {
+	precedence, err := getConfigPrecedence()
 	if err != nil {
 		return err
 	}
 
+	if precedence[len(precedence)-1] == configSourceEnv {
+		Config.AppliedEnvOverrides, err = processEnvVars()
+		if err != nil {
+			return err
+		}
+	}
+
+	if g.LogVerbose && len(Config.AppliedEnvOverrides) > 0 {
+		color.Cyan("\nApplied ENV var overrides:")
+		err = u.PrintAsYAML(Config.AppliedEnvOverrides)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 // ProcessConfig processes and checks CLI configuration
 func ProcessConfig(configAndStacksInfo ConfigAndStacksInfo) error {
 	// Process ENV vars
-	err := processEnvVars()
+	err := ApplyEnvVars()
 	if err != nil {
 		return err
 	}
@@ -176,16 +293,25 @@ func ProcessConfig(configAndStacksInfo ConfigAndStacksInfo) error {
 		return err
 	}
 
+	normalizeConfigPaths()
+
+	// Resolve the stack alias (if the provided stack is an alias)
+	configAndStacksInfo.Stack = ResolveStackAlias(configAndStacksInfo.Stack)
+
 	// Convert stacks base path to absolute path
-	stacksBasePath := path.Join(Config.BasePath, Config.Stacks.BasePath)
+	stacksBasePath := filepath.Join(Config.BasePath, Config.Stacks.BasePath)
 	stacksBaseAbsPath, err := filepath.Abs(stacksBasePath)
 	if err != nil {
 		return err
 	}
 	ProcessedConfig.StacksBaseAbsolutePath = stacksBaseAbsPath
 
-	// Convert the included stack paths to absolute paths
-	includeStackAbsPaths, err := u.JoinAbsolutePathWithPaths(stacksBaseAbsPath, Config.Stacks.IncludedPaths)
+	// Convert the included stack paths (including the paths from 'stacks.included_paths_file') to absolute paths
+	includedStackPaths, err := getIncludedStackPaths()
+	if err != nil {
+		return err
+	}
+	includeStackAbsPaths, err := u.JoinAbsolutePathWithPaths(stacksBaseAbsPath, includedStackPaths)
 	if err != nil {
 		return err
 	}
@@ -199,7 +325,7 @@ func ProcessConfig(configAndStacksInfo ConfigAndStacksInfo) error {
 	ProcessedConfig.ExcludeStackAbsolutePaths = excludeStackAbsPaths
 
 	// Convert terraform dir to absolute path
-	terraformBasePath := path.Join(Config.BasePath, Config.Components.Terraform.BasePath)
+	terraformBasePath := filepath.Join(Config.BasePath, Config.Components.Terraform.BasePath)
 	terraformDirAbsPath, err := filepath.Abs(terraformBasePath)
 	if err != nil {
 		return err
@@ -207,7 +333,7 @@ func ProcessConfig(configAndStacksInfo ConfigAndStacksInfo) error {
 	ProcessedConfig.TerraformDirAbsolutePath = terraformDirAbsPath
 
 	// Convert helmfile dir to absolute path
-	helmfileBasePath := path.Join(Config.BasePath, Config.Components.Helmfile.BasePath)
+	helmfileBasePath := filepath.Join(Config.BasePath, Config.Components.Helmfile.BasePath)
 	helmfileDirAbsPath, err := filepath.Abs(helmfileBasePath)
 	if err != nil {
 		return err
@@ -225,6 +351,15 @@ func ProcessConfig(configAndStacksInfo ConfigAndStacksInfo) error {
 		return err
 	}
 
+	// If stack config files are not required (`stacks.require_stacks: false`), the components are targeted directly without stacks
+	if len(stackConfigFilesAbsolutePaths) < 1 && !Config.Stacks.RequireStacks {
+		l.Debug("\nNo stack config files found. Processing the component without stacks ('stacks.require_stacks' is 'false')")
+		ProcessedConfig.StackConfigFilesAbsolutePaths = nil
+		ProcessedConfig.StackConfigFilesRelativePaths = nil
+		ProcessedConfig.StackType = "None"
+		return nil
+	}
+
 	if len(stackConfigFilesAbsolutePaths) < 1 {
 		j, err := yaml.Marshal(includeStackAbsPaths)
 		if err != nil {
@@ -276,9 +411,14 @@ func ProcessConfig(configAndStacksInfo ConfigAndStacksInfo) error {
 		}
 	}
 
+	// The output format is validated even if the final CLI config is not printed
+	outputFormat, err := getOutputFormat()
+	if err != nil {
+		return err
+	}
+
 	if g.LogVerbose {
-		color.Cyan("\nFinal CLI configuration:")
-		err = u.PrintAsYAML(Config)
+		err = printFinalConfig(os.Stderr, outputFormat)
 		if err != nil {
 			return err
 		}
@@ -287,10 +427,46 @@ func ProcessConfig(configAndStacksInfo ConfigAndStacksInfo) error {
 	return nil
 }
 
+// getOutputFormat returns the format of the final CLI config output specified by `ATMOS_OUTPUT_FORMAT` ENV var.
+// Supported formats are `yaml` (default), `json` and `none` (the config is not written).
+// The default is `yaml` (not `json`) for compatibility, since atmos has always printed the final CLI config as YAML
+func getOutputFormat() (string, error) {
+	outputFormat := os.Getenv("ATMOS_OUTPUT_FORMAT")
+	if len(outputFormat) == 0 {
+		return "yaml", nil
+	}
+
+	switch outputFormat {
+	case "none", "yaml", "json":
+		return outputFormat, nil
+	default:
+		return "", errors.New(fmt.Sprintf("invalid value '%s' of the ENV var ATMOS_OUTPUT_FORMAT. Supported values are 'yaml', 'json' and 'none'", outputFormat))
+	}
+}
+
+// printFinalConfig writes the final CLI config to the provided writer in the provided format (see getOutputFormat)
+func printFinalConfig(w io.Writer, outputFormat string) error {
+	header := color.New(color.FgCyan)
+	if !l.UseColors(w) {
+		header.DisableColor()
+	}
+
+	switch outputFormat {
+	case "yaml":
+		_, _ = header.Fprintln(w, "\nFinal CLI configuration:")
+		return u.FprintAsYAML(w, Config)
+	case "json":
+		_, _ = header.Fprintln(w, "\nFinal CLI configuration:")
+		return u.FprintAsJSON(w, Config)
+	default:
+		return nil
+	}
+}
+
 // ProcessConfigForSpacelift processes config for Spacelift
 func ProcessConfigForSpacelift() error {
 	// Process ENV vars
-	err := processEnvVars()
+	err := ApplyEnvVars()
 	if err != nil {
 		return err
 	}
@@ -301,16 +477,22 @@ func ProcessConfigForSpacelift() error {
 		return err
 	}
 
+	normalizeConfigPaths()
+
 	// Convert stacks base path to absolute path
-	stacksBasePath := path.Join(Config.BasePath, Config.Stacks.BasePath)
+	stacksBasePath := filepath.Join(Config.BasePath, Config.Stacks.BasePath)
 	stacksBaseAbsPath, err := filepath.Abs(stacksBasePath)
 	if err != nil {
 		return err
 	}
 	ProcessedConfig.StacksBaseAbsolutePath = stacksBaseAbsPath
 
-	// Convert the included stack paths to absolute paths
-	includeStackAbsPaths, err := u.JoinAbsolutePathWithPaths(stacksBaseAbsPath, Config.Stacks.IncludedPaths)
+	// Convert the included stack paths (including the paths from 'stacks.included_paths_file') to absolute paths
+	includedStackPaths, err := getIncludedStackPaths()
+	if err != nil {
+		return err
+	}
+	includeStackAbsPaths, err := u.JoinAbsolutePathWithPaths(stacksBaseAbsPath, includedStackPaths)
 	if err != nil {
 		return err
 	}
@@ -324,7 +506,7 @@ func ProcessConfigForSpacelift() error {
 	ProcessedConfig.ExcludeStackAbsolutePaths = excludeStackAbsPaths
 
 	// Convert terraform dir to absolute path
-	terraformBasePath := path.Join(Config.BasePath, Config.Components.Terraform.BasePath)
+	terraformBasePath := filepath.Join(Config.BasePath, Config.Components.Terraform.BasePath)
 	terraformDirAbsPath, err := filepath.Abs(terraformBasePath)
 	if err != nil {
 		return err
@@ -332,7 +514,7 @@ func ProcessConfigForSpacelift() error {
 	ProcessedConfig.TerraformDirAbsolutePath = terraformDirAbsPath
 
 	// Convert helmfile dir to absolute path
-	helmfileBasePath := path.Join(Config.BasePath, Config.Components.Helmfile.BasePath)
+	helmfileBasePath := filepath.Join(Config.BasePath, Config.Components.Helmfile.BasePath)
 	helmfileDirAbsPath, err := filepath.Abs(helmfileBasePath)
 	if err != nil {
 		return err
@@ -349,7 +531,7 @@ func ProcessConfigForSpacelift() error {
 		return err
 	}
 
-	if len(stackConfigFilesAbsolutePaths) < 1 {
+	if len(stackConfigFilesAbsolutePaths) < 1 && Config.Stacks.RequireStacks {
 		j, err := yaml.Marshal(includeStackAbsPaths)
 		if err != nil {
 			return err
@@ -366,20 +548,82 @@ func ProcessConfigForSpacelift() error {
 	return nil
 }
 
+// processSystemDirConfig processes the CLI config in the system dir (`/usr/local/etc/atmos` on Linux, `%LOCALAPPDATA%/atmos` on Windows)
+func processSystemDirConfig(configFileName string, v *viper.Viper) error {
+	configFilePath := ""
+
+	// https://pureinfotech.com/list-environment-variables-windows-10/
+	// https://docs.microsoft.com/en-us/windows/deployment/usmt/usmt-recognized-environment-variables
+	// https://softwareengineering.stackexchange.com/questions/299869/where-is-the-appropriate-place-to-put-application-configuration-files-for-each-p
+	// https://stackoverflow.com/questions/37946282/why-does-appdata-in-windows-7-seemingly-points-to-wrong-folder
+	if runtime.GOOS == "windows" {
+		appDataDir := os.Getenv(g.WindowsAppDataEnvVar)
+		if len(appDataDir) > 0 {
+			configFilePath = appDataDir
+		}
+	} else {
+		configFilePath = g.SystemDirConfigFilePath
+	}
+
+	if len(configFilePath) == 0 {
+		return nil
+	}
+	return processConfigFile(filepath.Join(configFilePath, configFileName), v)
+}
+
+// processHomeDirConfig processes the CLI config in the user's HOME dir (~/.atmos)
+func processHomeDirConfig(configFileName string, v *viper.Viper) error {
+	configFilePath, err := homedir.Dir()
+	if err != nil {
+		return err
+	}
+	return processConfigFile(filepath.Join(configFilePath, ".atmos", configFileName), v)
+}
+
+// processCurrentDirConfigs processes the CLI config in the current dir and the config files specified with the `--config` command-line flag
+func processCurrentDirConfigs(configFileName string, configFiles []string, v *viper.Viper) error {
+	configFilePath, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	err = processConfigFile(filepath.Join(configFilePath, configFileName), v)
+	if err != nil {
+		return err
+	}
+
+	for _, configFile := range configFiles {
+		if !u.FileExists(configFile) {
+			return errors.New(fmt.Sprintf("config file '%s' specified with the '%s' flag does not exist", configFile, g.ConfigFlag))
+		}
+		err = processConfigFile(configFile, v)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetConfigFileName returns the CLI config file name from `ATMOS_CONFIG_FILE_NAME` ENV var, or `atmos.yaml` if the ENV var is not set
+func GetConfigFileName() string {
+	configFileName := os.Getenv("ATMOS_CONFIG_FILE_NAME")
+	if len(configFileName) > 0 {
+		l.Debug("Found ENV var ATMOS_CONFIG_FILE_NAME=%s", configFileName)
+		return configFileName
+	}
+	return g.ConfigFileName
+}
+
 // https://github.com/NCAR/go-figure
 // https://github.com/spf13/viper/issues/181
 // https://medium.com/@bnprashanth256/reading-configuration-files-and-environment-variables-in-go-golang-c2607f912b63
 func processConfigFile(path string, v *viper.Viper) error {
 	if !u.FileExists(path) {
-		if g.LogVerbose {
-			fmt.Println(fmt.Sprintf("No config found in %s", path))
-		}
+		l.DebugDim("No config found in %s", path)
 		return nil
 	}
 
-	if g.LogVerbose {
-		color.Green("Found config in %s", path)
-	}
+	l.DebugHighlight("Found config in %s", path)
 
 	reader, err := os.Open(path)
 	if err != nil {
@@ -398,9 +642,7 @@ func processConfigFile(path string, v *viper.Viper) error {
 		return err
 	}
 
-	if g.LogVerbose {
-		color.Green("Processed config %s", path)
-	}
+	l.DebugHighlight("Processed config %s", path)
 
 	return nil
 }
@@ -0,0 +1,540 @@
+package config
+
+import (
+	"bytes"
+	g "github.com/cloudposse/atmos/pkg/globals"
+	l "github.com/cloudposse/atmos/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func writeTestConfigFile(t *testing.T, dir string, name string, content string) string {
+	filePath := path.Join(dir, name)
+	err := os.WriteFile(filePath, []byte(content), 0644)
+	assert.Nil(t, err)
+	return filePath
+}
+
+func TestInitConfigWithConfigFile(t *testing.T) {
+	dir := t.TempDir()
+
+	configFile := writeTestConfigFile(t, dir, "extra.yaml", `
+base_path: "./one"
+stacks:
+  name_pattern: "{tenant}-{stage}"
+`)
+
+	var configAndStacksInfo ConfigAndStacksInfo
+	configAndStacksInfo.ConfigFiles = []string{configFile}
+
+	err := InitConfigWithArgs(configAndStacksInfo)
+	assert.Nil(t, err)
+	assert.Equal(t, "./one", Config.BasePath)
+	assert.Equal(t, "{tenant}-{stage}", Config.Stacks.NamePattern)
+	// Values not defined in the config file come from the defaults
+	assert.Equal(t, "components/terraform", Config.Components.Terraform.BasePath)
+}
+
+func TestInitConfigWithMultipleConfigFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	configFile1 := writeTestConfigFile(t, dir, "extra1.yaml", `
+base_path: "./one"
+stacks:
+  name_pattern: "{tenant}-{stage}"
+`)
+
+	configFile2 := writeTestConfigFile(t, dir, "extra2.yaml", `
+base_path: "./two"
+components:
+  terraform:
+    base_path: "infra/terraform"
+`)
+
+	var configAndStacksInfo ConfigAndStacksInfo
+	configAndStacksInfo.ConfigFiles = []string{configFile1, configFile2}
+
+	err := InitConfigWithArgs(configAndStacksInfo)
+	assert.Nil(t, err)
+	// The files are merged in the order given
+	assert.Equal(t, "./two", Config.BasePath)
+	assert.Equal(t, "{tenant}-{stage}", Config.Stacks.NamePattern)
+	assert.Equal(t, "infra/terraform", Config.Components.Terraform.BasePath)
+
+	configAndStacksInfo.ConfigFiles = []string{configFile2, configFile1}
+
+	err = InitConfigWithArgs(configAndStacksInfo)
+	assert.Nil(t, err)
+	assert.Equal(t, "./one", Config.BasePath)
+	assert.Equal(t, "infra/terraform", Config.Components.Terraform.BasePath)
+}
+
+func TestInitConfigWithMissingConfigFile(t *testing.T) {
+	var configAndStacksInfo ConfigAndStacksInfo
+	configAndStacksInfo.ConfigFiles = []string{path.Join(t.TempDir(), "missing.yaml")}
+
+	err := InitConfigWithArgs(configAndStacksInfo)
+	assert.NotNil(t, err)
+}
+
+func TestInitConfigSchemaValidation(t *testing.T) {
+	configFile := writeTestConfigFile(t, t.TempDir(), "invalid.yaml", `
+stacks:
+  unknown_setting: true
+`)
+
+	var configAndStacksInfo ConfigAndStacksInfo
+	configAndStacksInfo.ConfigFiles = []string{configFile}
+
+	err := InitConfigWithArgs(configAndStacksInfo)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "'stacks': Additional property unknown_setting is not allowed")
+
+	configAndStacksInfo.NoSchemaValidation = true
+	err = InitConfigWithArgs(configAndStacksInfo)
+	assert.Nil(t, err)
+}
+
+func TestInitConfigAppliedEnvOverrides(t *testing.T) {
+	t.Setenv("ATMOS_STACKS_NAME_PATTERN", "{tenant}-{stage}")
+	t.Setenv("ATMOS_COMPONENTS_TERRAFORM_BASE_PATH", "infra/terraform")
+
+	err := InitConfig()
+	assert.Nil(t, err)
+	// The ENV vars are applied after the config files
+	assert.Empty(t, Config.AppliedEnvOverrides)
+
+	err = ApplyEnvVars()
+	assert.Nil(t, err)
+	assert.Equal(t, "{tenant}-{stage}", Config.Stacks.NamePattern)
+	assert.Equal(t, "infra/terraform", Config.Components.Terraform.BasePath)
+	assert.Equal(t, map[string]string{
+		"ATMOS_STACKS_NAME_PATTERN":            "{tenant}-{stage}",
+		"ATMOS_COMPONENTS_TERRAFORM_BASE_PATH": "infra/terraform",
+	}, Config.AppliedEnvOverrides)
+}
+
+func TestEnsureConfig(t *testing.T) {
+	ResetConfigForTesting()
+	t.Cleanup(ResetConfigForTesting)
+
+	t.Setenv("ATMOS_STACKS_NAME_PATTERN", "{tenant}-{stage}")
+
+	var wg sync.WaitGroup
+	results := make([]Configuration, 10)
+	errs := make([]error, 10)
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = EnsureConfig()
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < 10; i++ {
+		assert.Nil(t, errs[i])
+		assert.Equal(t, "{tenant}-{stage}", results[i].Stacks.NamePattern)
+	}
+
+	// The cached config is returned on subsequent calls
+	t.Setenv("ATMOS_STACKS_NAME_PATTERN", "{environment}-{stage}")
+	config, err := EnsureConfig()
+	assert.Nil(t, err)
+	assert.Equal(t, "{tenant}-{stage}", config.Stacks.NamePattern)
+
+	// After the reset, the config is initialized again
+	ResetConfigForTesting()
+	config, err = EnsureConfig()
+	assert.Nil(t, err)
+	assert.Equal(t, "{environment}-{stage}", config.Stacks.NamePattern)
+}
+
+func TestInitConfigWithConfigFileNameFromEnv(t *testing.T) {
+	dir := t.TempDir()
+
+	writeTestConfigFile(t, dir, "atmos.yaml", `
+stacks:
+  name_pattern: "{tenant}-{environment}-{stage}"
+`)
+	writeTestConfigFile(t, dir, "atmos.prod.yaml", `
+stacks:
+  name_pattern: "{environment}-{stage}"
+`)
+
+	cwd, err := os.Getwd()
+	assert.Nil(t, err)
+	err = os.Chdir(dir)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_ = os.Chdir(cwd)
+	})
+
+	t.Setenv("ATMOS_CONFIG_FILE_NAME", "atmos.prod.yaml")
+	err = InitConfig()
+	assert.Nil(t, err)
+	assert.Equal(t, "{environment}-{stage}", Config.Stacks.NamePattern)
+
+	// Falls back to `atmos.yaml` when the ENV var is not set
+	t.Setenv("ATMOS_CONFIG_FILE_NAME", "")
+	err = InitConfig()
+	assert.Nil(t, err)
+	assert.Equal(t, "{tenant}-{environment}-{stage}", Config.Stacks.NamePattern)
+}
+
+func TestProcessLogsConfig(t *testing.T) {
+	level := l.GetLevel()
+	logVerbose := g.LogVerbose
+	t.Cleanup(func() {
+		l.SetLevel(level)
+		g.LogVerbose = logVerbose
+	})
+
+	err := processLogsConfig("")
+	assert.Nil(t, err)
+	assert.Equal(t, l.InfoLevel, l.GetLevel())
+	assert.False(t, g.LogVerbose)
+
+	// `ATMOS_LOGS_VERBOSE=true` maps to `debug`
+	t.Setenv("ATMOS_LOGS_VERBOSE", "true")
+	err = processLogsConfig("")
+	assert.Nil(t, err)
+	assert.Equal(t, l.DebugLevel, l.GetLevel())
+	assert.True(t, g.LogVerbose)
+
+	// `ATMOS_LOGS_LEVEL` ENV var overrides `ATMOS_LOGS_VERBOSE`, and the command-line flag overrides the ENV var
+	t.Setenv("ATMOS_LOGS_LEVEL", "trace")
+	err = processLogsConfig("")
+	assert.Nil(t, err)
+	assert.Equal(t, l.TraceLevel, l.GetLevel())
+
+	err = processLogsConfig("error")
+	assert.Nil(t, err)
+	assert.Equal(t, l.ErrorLevel, l.GetLevel())
+	assert.False(t, g.LogVerbose)
+
+	err = processLogsConfig("verbose")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Allowed values are: trace, debug, info, warn, error")
+}
+
+func TestInitConfigSuppressesMessagesBelowLogsLevel(t *testing.T) {
+	level := l.GetLevel()
+	logVerbose := g.LogVerbose
+	output := l.Output
+	t.Cleanup(func() {
+		l.SetLevel(level)
+		g.LogVerbose = logVerbose
+		l.Output = output
+	})
+
+	var buf bytes.Buffer
+	l.Output = &buf
+
+	dir := t.TempDir()
+	configFile := writeTestConfigFile(t, dir, "extra.yaml", `
+base_path: "./one"
+`)
+
+	t.Setenv("ATMOS_STACKS_NAME_PATTERN", "{tenant}-{stage}")
+	err := InitConfigWithArgs(ConfigAndStacksInfo{ConfigFiles: []string{configFile}, LogsLevel: "warn"})
+	assert.Nil(t, err)
+	err = ApplyEnvVars()
+	assert.Nil(t, err)
+	assert.Empty(t, buf.String())
+
+	err = InitConfigWithArgs(ConfigAndStacksInfo{ConfigFiles: []string{configFile}, LogsLevel: "info"})
+	assert.Nil(t, err)
+	err = ApplyEnvVars()
+	assert.Nil(t, err)
+	assert.Contains(t, buf.String(), "Found ENV var ATMOS_STACKS_NAME_PATTERN={tenant}-{stage}")
+	assert.NotContains(t, buf.String(), "Found config in "+configFile)
+}
+
+func TestPrintFinalConfig(t *testing.T) {
+	config := Config
+	t.Cleanup(func() {
+		Config = config
+	})
+	Config.Stacks.NamePattern = "{tenant}-{environment}-{stage}"
+
+	var buf bytes.Buffer
+	err := printFinalConfig(&buf, "yaml")
+	assert.Nil(t, err)
+	assert.Contains(t, buf.String(), "name_pattern: '{tenant}-{environment}-{stage}'")
+
+	buf.Reset()
+	err = printFinalConfig(&buf, "json")
+	assert.Nil(t, err)
+	assert.Contains(t, buf.String(), `"name_pattern": "{tenant}-{environment}-{stage}"`)
+
+	buf.Reset()
+	err = printFinalConfig(&buf, "none")
+	assert.Nil(t, err)
+	assert.Empty(t, buf.String())
+}
+
+func TestGetOutputFormat(t *testing.T) {
+	t.Setenv("ATMOS_OUTPUT_FORMAT", "")
+	outputFormat, err := getOutputFormat()
+	assert.Nil(t, err)
+	assert.Equal(t, "yaml", outputFormat)
+
+	t.Setenv("ATMOS_OUTPUT_FORMAT", "json")
+	outputFormat, err = getOutputFormat()
+	assert.Nil(t, err)
+	assert.Equal(t, "json", outputFormat)
+
+	t.Setenv("ATMOS_OUTPUT_FORMAT", "xml")
+	_, err = getOutputFormat()
+	assert.NotNil(t, err)
+}
+
+func TestProcessConfigValidatesOutputFormat(t *testing.T) {
+	config := Config
+	processedConfig := ProcessedConfig
+	logVerbose := g.LogVerbose
+	t.Cleanup(func() {
+		Config = config
+		ProcessedConfig = processedConfig
+		g.LogVerbose = logVerbose
+	})
+
+	dir := t.TempDir()
+	configFile := writeTestConfigFile(t, dir, "atmos.yaml", `
+base_path: "`+dir+`"
+stacks:
+  base_path: "stacks"
+  included_paths:
+    - "**/*"
+  name_pattern: "{stage}"
+`)
+	assert.Nil(t, os.MkdirAll(path.Join(dir, "stacks"), 0755))
+	writeTestConfigFile(t, path.Join(dir, "stacks"), "dev.yaml", "vars:\n  stage: dev\n")
+
+	err := InitConfigWithArgs(ConfigAndStacksInfo{ConfigFiles: []string{configFile}})
+	assert.Nil(t, err)
+
+	// The invalid output format is an error even if the final CLI config is not printed
+	g.LogVerbose = false
+	t.Setenv("ATMOS_OUTPUT_FORMAT", "xml")
+	err = ProcessConfig(ConfigAndStacksInfo{Stack: "dev"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "ATMOS_OUTPUT_FORMAT")
+
+	t.Setenv("ATMOS_OUTPUT_FORMAT", "none")
+	err = ProcessConfig(ConfigAndStacksInfo{Stack: "dev"})
+	assert.Nil(t, err)
+}
+
+func TestProcessConfigWithStackNamePatternFlag(t *testing.T) {
+	config := Config
+	processedConfig := ProcessedConfig
+	t.Cleanup(func() {
+		Config = config
+		ProcessedConfig = processedConfig
+	})
+
+	dir := t.TempDir()
+	configFile := writeTestConfigFile(t, dir, "atmos.yaml", `
+base_path: "`+dir+`"
+stacks:
+  base_path: "stacks"
+  included_paths:
+    - "**/*"
+  name_pattern: "{stage}"
+`)
+	assert.Nil(t, os.MkdirAll(path.Join(dir, "stacks"), 0755))
+	writeTestConfigFile(t, path.Join(dir, "stacks"), "dev.yaml", "vars:\n  tenant: tenant1\n  stage: dev\n")
+
+	err := InitConfigWithArgs(ConfigAndStacksInfo{ConfigFiles: []string{configFile}})
+	assert.Nil(t, err)
+
+	// The `--stack-name-pattern` flag overrides `stacks.name_pattern`
+	err = ProcessConfig(ConfigAndStacksInfo{Stack: "tenant1-dev", StackNamePattern: "{tenant}-{stage}"})
+	assert.Nil(t, err)
+	assert.Equal(t, "{tenant}-{stage}", Config.Stacks.NamePattern)
+
+	// The pattern from the flag is validated
+	err = ProcessConfig(ConfigAndStacksInfo{Stack: "tenant1-dev", StackNamePattern: "{region}"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "{region}")
+}
+
+func TestInitConfigUseWorkspaces(t *testing.T) {
+	dir := t.TempDir()
+
+	cwd, err := os.Getwd()
+	assert.Nil(t, err)
+	err = os.Chdir(dir)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_ = os.Chdir(cwd)
+	})
+
+	// Terraform workspaces are used by default
+	err = InitConfig()
+	assert.Nil(t, err)
+	assert.True(t, Config.Components.Terraform.UseWorkspaces)
+
+	t.Setenv("ATMOS_COMPONENTS_TERRAFORM_USE_WORKSPACES", "false")
+	err = InitConfig()
+	assert.Nil(t, err)
+	err = ApplyEnvVars()
+	assert.Nil(t, err)
+	assert.False(t, Config.Components.Terraform.UseWorkspaces)
+}
+
+func TestConfigurationAsEnvVars(t *testing.T) {
+	savedConfig := Config
+	level := l.GetLevel()
+	t.Cleanup(func() {
+		Config = savedConfig
+		l.SetLevel(level)
+	})
+	l.SetLevel(l.WarnLevel)
+
+	config := Configuration{
+		BasePath: "/infra",
+		Stacks: Stacks{
+			BasePath:      "stacks",
+			IncludedPaths: []string{"orgs/**/*", "teams/**/*"},
+			NamePattern:   "{tenant}-{stage}",
+			Aliases:       map[string]string{"prod": "tenant1-prod", "dev": "tenant1-dev"},
+		},
+		Components: Components{
+			Terraform: Terraform{
+				BasePath:      "components/terraform",
+				UseWorkspaces: true,
+			},
+		},
+	}
+
+	envVars := config.AsEnvVars()
+	assert.Contains(t, envVars, "ATMOS_BASE_PATH=/infra")
+	assert.Contains(t, envVars, "ATMOS_STACKS_INCLUDED_PATHS=orgs/**/*,teams/**/*")
+	assert.Contains(t, envVars, "ATMOS_STACKS_NAME_PATTERN={tenant}-{stage}")
+	assert.Contains(t, envVars, "ATMOS_STACKS_ALIASES=dev=tenant1-dev,prod=tenant1-prod")
+	assert.Contains(t, envVars, "ATMOS_COMPONENTS_TERRAFORM_BASE_PATH=components/terraform")
+	assert.Contains(t, envVars, "ATMOS_COMPONENTS_TERRAFORM_USE_WORKSPACES=true")
+	assert.Contains(t, envVars, "ATMOS_SETTINGS_STRICT_MODE=false")
+	assert.Contains(t, envVars, "ATMOS_LOGS_LEVEL=warn")
+	// Empty values are skipped
+	assert.NotContains(t, strings.Join(envVars, "\n"), "ATMOS_STACKS_EXCLUDED_PATHS")
+
+	// The ENV vars are read back by atmos
+	for _, envVar := range envVars {
+		parts := strings.SplitN(envVar, "=", 2)
+		t.Setenv(parts[0], parts[1])
+	}
+	_, err := processEnvVars()
+	assert.Nil(t, err)
+	assert.Equal(t, config.Stacks.IncludedPaths, Config.Stacks.IncludedPaths)
+	assert.Equal(t, config.Stacks.Aliases, Config.Stacks.Aliases)
+	assert.Equal(t, config.Stacks.NamePattern, Config.Stacks.NamePattern)
+}
+
+func TestCheckRequiredVersion(t *testing.T) {
+	// The check is skipped if the constraint is not provided
+	assert.Nil(t, checkRequiredVersion("", "1.2.0"))
+
+	// Satisfied
+	assert.Nil(t, checkRequiredVersion(">= 1.2.0", "1.2.0"))
+	assert.Nil(t, checkRequiredVersion(">= 1.2.0, < 2.0.0", "v1.10.3"))
+
+	// Unsatisfied
+	err := checkRequiredVersion(">= 1.2.0", "1.1.9")
+	assert.NotNil(t, err)
+	assert.Equal(t, "the CLI config requires atmos version '>= 1.2.0', but the running atmos version is '1.1.9'. Please upgrade atmos", err.Error())
+
+	// Malformed constraint
+	err = checkRequiredVersion("at least 1.2", "1.2.0")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "invalid 'required_version' constraint 'at least 1.2'")
+}
+
+func TestInitConfigWithRequiredVersion(t *testing.T) {
+	dir := t.TempDir()
+	atmosVersion := AtmosVersion
+	t.Cleanup(func() {
+		AtmosVersion = atmosVersion
+	})
+	AtmosVersion = "1.1.0"
+
+	configFile := writeTestConfigFile(t, dir, "atmos.yaml", `
+required_version: ">= 1.2.0"
+stacks:
+  name_pattern: "{stage}"
+`)
+
+	err := InitConfigWithArgs(ConfigAndStacksInfo{ConfigFiles: []string{configFile}})
+	assert.NotNil(t, err)
+
+	AtmosVersion = "1.2.0"
+	err = InitConfigWithArgs(ConfigAndStacksInfo{ConfigFiles: []string{configFile}})
+	assert.Nil(t, err)
+	assert.Equal(t, ">= 1.2.0", Config.RequiredVersion)
+}
+
+func TestInitConfigWithConfigPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	writeTestConfigFile(t, dir, "atmos.yaml", `
+stacks:
+  name_pattern: "{tenant}-{stage}"
+`)
+
+	cwd, err := os.Getwd()
+	assert.Nil(t, err)
+	err = os.Chdir(dir)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_ = os.Chdir(cwd)
+	})
+
+	t.Setenv("ATMOS_STACKS_NAME_PATTERN", "{environment}-{stage}")
+	t.Setenv("ATMOS_COMPONENTS_TERRAFORM_BASE_PATH", "infra/terraform")
+
+	// By default, the ENV vars override the config in the current dir
+	err = InitConfig()
+	assert.Nil(t, err)
+	err = ApplyEnvVars()
+	assert.Nil(t, err)
+	assert.Equal(t, "{environment}-{stage}", Config.Stacks.NamePattern)
+
+	// The config in the current dir overrides the ENV vars
+	t.Setenv("ATMOS_CONFIG_PRECEDENCE", "system, home, env, cwd")
+	err = InitConfig()
+	assert.Nil(t, err)
+	assert.Equal(t, "{tenant}-{stage}", Config.Stacks.NamePattern)
+	// The ENV vars still override the values not defined in the config in the current dir
+	assert.Equal(t, "infra/terraform", Config.Components.Terraform.BasePath)
+	assert.Equal(t, "infra/terraform", Config.AppliedEnvOverrides["ATMOS_COMPONENTS_TERRAFORM_BASE_PATH"])
+
+	// The ENV vars are not applied again
+	err = ApplyEnvVars()
+	assert.Nil(t, err)
+	assert.Equal(t, "{tenant}-{stage}", Config.Stacks.NamePattern)
+}
+
+func TestParseConfigPrecedence(t *testing.T) {
+	precedence, err := parseConfigPrecedence("env,cwd,home,system")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"env", "cwd", "home", "system"}, precedence)
+
+	_, err = parseConfigPrecedence("system,home,cwd,env,remote")
+	assert.NotNil(t, err)
+	assert.Equal(t, "invalid config source 'remote' in the ENV var ATMOS_CONFIG_PRECEDENCE. Supported sources are: system, home, cwd, env", err.Error())
+
+	_, err = parseConfigPrecedence("system,home,cwd,home")
+	assert.NotNil(t, err)
+	assert.Equal(t, "the config source 'home' is specified more than once in the ENV var ATMOS_CONFIG_PRECEDENCE", err.Error())
+
+	_, err = parseConfigPrecedence("system,home,cwd")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "the config source 'env' is missing")
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	l "github.com/cloudposse/atmos/pkg/logger"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AsEnvVars returns the CLI config as a list of ENV vars in the `KEY=VALUE` format (as used by `os/exec`).
+// The ENV vars are the same as the ENV vars read by atmos, so the config can be passed to child processes.
+// Lists are joined with `,`, the stack aliases are joined as `alias=stack` pairs with `,`. Empty strings and lists are skipped
+func (c Configuration) AsEnvVars() []string {
+	var res []string
+
+	addString := func(key string, value string) {
+		if len(value) > 0 {
+			res = append(res, fmt.Sprintf("%s=%s", key, value))
+		}
+	}
+
+	addList := func(key string, value []string) {
+		if len(value) > 0 {
+			res = append(res, fmt.Sprintf("%s=%s", key, strings.Join(value, ",")))
+		}
+	}
+
+	addBool := func(key string, value bool) {
+		res = append(res, fmt.Sprintf("%s=%s", key, strconv.FormatBool(value)))
+	}
+
+	addString("ATMOS_BASE_PATH", c.BasePath)
+
+	addString("ATMOS_STACKS_BASE_PATH", c.Stacks.BasePath)
+	addList("ATMOS_STACKS_INCLUDED_PATHS", c.Stacks.IncludedPaths)
+	addString("ATMOS_STACKS_INCLUDED_PATHS_FILE", c.Stacks.IncludedPathsFile)
+	addList("ATMOS_STACKS_EXCLUDED_PATHS", c.Stacks.ExcludedPaths)
+	addString("ATMOS_STACKS_NAME_PATTERN", c.Stacks.NamePattern)
+
+	var aliases []string
+	for alias, stack := range c.Stacks.Aliases {
+		aliases = append(aliases, fmt.Sprintf("%s=%s", alias, stack))
+	}
+	sort.Strings(aliases)
+	addList("ATMOS_STACKS_ALIASES", aliases)
+	addBool("ATMOS_STACKS_REQUIRE_STACKS", c.Stacks.RequireStacks)
+
+	addString("ATMOS_COMPONENTS_TERRAFORM_BASE_PATH", c.Components.Terraform.BasePath)
+	addBool("ATMOS_COMPONENTS_TERRAFORM_APPLY_AUTO_APPROVE", c.Components.Terraform.ApplyAutoApprove)
+	addBool("ATMOS_COMPONENTS_TERRAFORM_DEPLOY_RUN_INIT", c.Components.Terraform.DeployRunInit)
+	addBool("ATMOS_COMPONENTS_TERRAFORM_AUTO_GENERATE_BACKEND_FILE", c.Components.Terraform.AutoGenerateBackendFile)
+	addBool("ATMOS_COMPONENTS_TERRAFORM_USE_WORKSPACES", c.Components.Terraform.UseWorkspaces)
+	addBool("ATMOS_COMPONENTS_TERRAFORM_DISCOVER_VAR_FILES", c.Components.Terraform.DiscoverVarFiles)
+	addString("ATMOS_TERRAFORM_COMMAND", c.Components.Terraform.Command)
+
+	addList("ATMOS_COMPONENTS_ALLOWLIST", c.Components.Allowlist)
+	addList("ATMOS_COMPONENTS_DENYLIST", c.Components.Denylist)
+
+	addString("ATMOS_COMPONENTS_HELMFILE_BASE_PATH", c.Components.Helmfile.BasePath)
+	addString("ATMOS_COMPONENTS_HELMFILE_KUBECONFIG_PATH", c.Components.Helmfile.KubeconfigPath)
+	addString("ATMOS_COMPONENTS_HELMFILE_HELM_AWS_PROFILE_PATTERN", c.Components.Helmfile.HelmAwsProfilePattern)
+	addString("ATMOS_COMPONENTS_HELMFILE_CLUSTER_NAME_PATTERN", c.Components.Helmfile.ClusterNamePattern)
+	addString("ATMOS_HELMFILE_COMMAND", c.Components.Helmfile.Command)
+
+	addString("ATMOS_WORKFLOWS_BASE_PATH", c.Workflows.BasePath)
+	addBool("ATMOS_WORKFLOWS_FAIL_FAST", c.Workflows.FailFast)
+
+	addBool("ATMOS_LOGS_VERBOSE", c.Logs.Verbose)
+	// The logs level is not a part of the CLI config, it's the current threshold of the logger
+	addString("ATMOS_LOGS_LEVEL", l.GetLevel().String())
+
+	addBool("ATMOS_SETTINGS_STRICT_MODE", c.Settings.StrictMode)
+	addString("ATMOS_SETTINGS_LIST_MERGE_STRATEGY", c.Settings.ListMergeStrategy)
+
+	return res
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	"os"
+	"sort"
+)
+
+// FormatConfigFile rewrites the CLI config file in the canonical format: the keys in all maps are sorted, and the indentation is 2 spaces.
+// The values and the comments are preserved, and the default values are not added. Formatting an already formatted file does not change it
+func FormatConfigFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	formatted, err := formatConfig(content)
+	if err != nil {
+		return errors.New(fmt.Sprintf("error formatting the CLI config file '%s': %v", path, err))
+	}
+
+	if bytes.Equal(content, formatted) {
+		return nil
+	}
+
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, formatted, fileInfo.Mode())
+}
+
+// formatConfig returns the CLI config in the canonical format
+func formatConfig(content []byte) ([]byte, error) {
+	var document yaml.Node
+	err := yaml.Unmarshal(content, &document)
+	if err != nil {
+		return nil, err
+	}
+
+	// Empty file
+	if len(document.Content) == 0 {
+		return content, nil
+	}
+
+	if document.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("the root element must be a map")
+	}
+
+	sortMapNodeKeys(&document)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	err = encoder.Encode(&document)
+	if err != nil {
+		return nil, err
+	}
+	err = encoder.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// sortMapNodeKeys recursively sorts the keys in all the YAML map nodes. The comments stay attached to their keys
+func sortMapNodeKeys(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode {
+		type keyValue struct {
+			key   *yaml.Node
+			value *yaml.Node
+		}
+
+		var pairs []keyValue
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			pairs = append(pairs, keyValue{key: node.Content[i], value: node.Content[i+1]})
+		}
+
+		sort.SliceStable(pairs, func(i, j int) bool {
+			return pairs[i].key.Value < pairs[j].key.Value
+		})
+
+		node.Content = node.Content[:0]
+		for _, pair := range pairs {
+			node.Content = append(node.Content, pair.key, pair.value)
+		}
+	}
+
+	for _, child := range node.Content {
+		sortMapNodeKeys(child)
+	}
+}
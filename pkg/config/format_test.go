@@ -0,0 +1,77 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+	"os"
+	"testing"
+)
+
+func TestFormatConfigFile(t *testing.T) {
+	configFile := writeTestConfigFile(t, t.TempDir(), "atmos.yaml", `stacks:
+    name_pattern: "{tenant}-{environment}-{stage}"
+    base_path: stacks
+    included_paths:
+        - "orgs/**/*"
+        - "catalog/**/*"
+# Components config
+components:
+    terraform:
+        # Can also be set using ATMOS_COMPONENTS_TERRAFORM_BASE_PATH
+        base_path: 'components/terraform'
+        apply_auto_approve: false
+base_path: "."
+`)
+
+	err := FormatConfigFile(configFile)
+	assert.Nil(t, err)
+
+	content, err := os.ReadFile(configFile)
+	assert.Nil(t, err)
+	assert.Equal(t, `base_path: "."
+# Components config
+components:
+  terraform:
+    apply_auto_approve: false
+    # Can also be set using ATMOS_COMPONENTS_TERRAFORM_BASE_PATH
+    base_path: 'components/terraform'
+stacks:
+  base_path: stacks
+  included_paths:
+    - "orgs/**/*"
+    - "catalog/**/*"
+  name_pattern: "{tenant}-{environment}-{stage}"
+`, string(content))
+
+	// Formatting is idempotent
+	err = FormatConfigFile(configFile)
+	assert.Nil(t, err)
+
+	contentAfterSecondRun, err := os.ReadFile(configFile)
+	assert.Nil(t, err)
+	assert.Equal(t, string(content), string(contentAfterSecondRun))
+}
+
+func TestFormatConfigFilePreservesValues(t *testing.T) {
+	original, err := os.ReadFile("../../atmos.yaml")
+	assert.Nil(t, err)
+
+	configFile := writeTestConfigFile(t, t.TempDir(), "atmos.yaml", string(original))
+	err = FormatConfigFile(configFile)
+	assert.Nil(t, err)
+
+	formatted, err := os.ReadFile(configFile)
+	assert.Nil(t, err)
+
+	var originalConfig, formattedConfig map[string]interface{}
+	assert.Nil(t, yaml.Unmarshal(original, &originalConfig))
+	assert.Nil(t, yaml.Unmarshal(formatted, &formattedConfig))
+	assert.Equal(t, originalConfig, formattedConfig)
+
+	err = FormatConfigFile(configFile)
+	assert.Nil(t, err)
+
+	formattedAfterSecondRun, err := os.ReadFile(configFile)
+	assert.Nil(t, err)
+	assert.Equal(t, string(formatted), string(formattedAfterSecondRun))
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	"os"
+	"strings"
+)
+
+// configKeyMigration describes a deprecated top-level CLI config key and the new (dot-separated) path of the key
+type configKeyMigration struct {
+	oldKey  string
+	newPath string
+}
+
+// configKeyMigrations are the known deprecated CLI config keys
+var configKeyMigrations = []configKeyMigration{
+	{oldKey: "TerraformDir", newPath: "components.terraform.base_path"},
+	{oldKey: "HelmfileDir", newPath: "components.helmfile.base_path"},
+	{oldKey: "StackDirs", newPath: "stacks.included_paths"},
+	{oldKey: "StackNamePattern", newPath: "stacks.name_pattern"},
+	{oldKey: "WorkflowsDir", newPath: "workflows.base_path"},
+}
+
+// MigrateConfig reads the CLI config file, replaces the deprecated keys with the new ones, and rewrites the file preserving the comments.
+// It returns `true` if the file was changed. Running it on an already migrated file does not change the file
+func MigrateConfig(path string) (bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	var document yaml.Node
+	err = yaml.Unmarshal(content, &document)
+	if err != nil {
+		return false, err
+	}
+
+	// Empty file
+	if len(document.Content) == 0 {
+		return false, nil
+	}
+
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return false, errors.New(fmt.Sprintf("invalid CLI config file '%s': the root element must be a map", path))
+	}
+
+	changed := false
+
+	for _, migration := range configKeyMigrations {
+		keyNode, valueNode := removeMapNodeKey(root, migration.oldKey)
+		if keyNode == nil {
+			continue
+		}
+
+		changed = true
+
+		newPathParts := strings.Split(migration.newPath, ".")
+		parentNode := root
+		for _, part := range newPathParts[:len(newPathParts)-1] {
+			parentNode, err = getOrCreateMapNode(parentNode, part)
+			if err != nil {
+				return false, errors.New(fmt.Sprintf("error migrating the key '%s' to '%s' in '%s': %v", migration.oldKey, migration.newPath, path, err))
+			}
+		}
+
+		newKey := newPathParts[len(newPathParts)-1]
+		if _, existingValueNode := findMapNodeKey(parentNode, newKey); existingValueNode != nil {
+			color.Yellow("Removed the deprecated key '%s' from '%s'. The new key '%s' is already defined and its value is kept",
+				migration.oldKey, path, migration.newPath)
+			continue
+		}
+
+		keyNode.Value = newKey
+		parentNode.Content = append(parentNode.Content, keyNode, valueNode)
+		color.Cyan("Migrated the deprecated key '%s' to '%s' in '%s'", migration.oldKey, migration.newPath, path)
+	}
+
+	if !changed {
+		return false, nil
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	err = encoder.Encode(&document)
+	if err != nil {
+		return false, err
+	}
+	err = encoder.Close()
+	if err != nil {
+		return false, err
+	}
+
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+
+	err = os.WriteFile(path, buf.Bytes(), fileInfo.Mode())
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// findMapNodeKey returns the key and value nodes for the provided key in the YAML map node
+func findMapNodeKey(mapNode *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	for i := 0; i+1 < len(mapNode.Content); i += 2 {
+		if mapNode.Content[i].Value == key {
+			return mapNode.Content[i], mapNode.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// removeMapNodeKey removes the provided key from the YAML map node and returns the removed key and value nodes
+func removeMapNodeKey(mapNode *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	for i := 0; i+1 < len(mapNode.Content); i += 2 {
+		if mapNode.Content[i].Value == key {
+			keyNode := mapNode.Content[i]
+			valueNode := mapNode.Content[i+1]
+			mapNode.Content = append(mapNode.Content[:i], mapNode.Content[i+2:]...)
+			return keyNode, valueNode
+		}
+	}
+	return nil, nil
+}
+
+// getOrCreateMapNode returns the map node for the provided key in the YAML map node, creating it if it does not exist
+func getOrCreateMapNode(mapNode *yaml.Node, key string) (*yaml.Node, error) {
+	if _, valueNode := findMapNodeKey(mapNode, key); valueNode != nil {
+		if valueNode.Kind == yaml.MappingNode {
+			return valueNode, nil
+		}
+		// Replace an empty value (e.g. `stacks:` without any keys) with a map
+		if valueNode.Kind == yaml.ScalarNode && (valueNode.Tag == "!!null" || len(valueNode.Value) == 0) {
+			valueNode.Kind = yaml.MappingNode
+			valueNode.Tag = "!!map"
+			valueNode.Value = ""
+			return valueNode, nil
+		}
+		return nil, errors.New(fmt.Sprintf("'%s' must be a map", key))
+	}
+
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+	valueNode := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	mapNode.Content = append(mapNode.Content, keyNode, valueNode)
+	return valueNode, nil
+}
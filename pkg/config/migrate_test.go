@@ -0,0 +1,85 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestMigrateConfig(t *testing.T) {
+	configFile := writeTestConfigFile(t, t.TempDir(), "atmos.yaml", `# Legacy CLI config
+base_path: "."
+
+# Terraform components
+TerraformDir: "components/terraform"
+StackNamePattern: "{tenant}-{environment}-{stage}"
+StackDirs:
+  - "**/*"
+
+stacks:
+  base_path: "stacks"
+
+logs:
+  verbose: false
+`)
+
+	changed, err := MigrateConfig(configFile)
+	assert.Nil(t, err)
+	assert.True(t, changed)
+
+	content, err := os.ReadFile(configFile)
+	assert.Nil(t, err)
+
+	// Comments are preserved
+	assert.Contains(t, string(content), "# Legacy CLI config")
+	assert.Contains(t, string(content), "# Terraform components")
+	assert.NotContains(t, string(content), "TerraformDir")
+
+	var config Configuration
+	err = yaml.Unmarshal(content, &config)
+	assert.Nil(t, err)
+	assert.Equal(t, ".", config.BasePath)
+	assert.Equal(t, "components/terraform", config.Components.Terraform.BasePath)
+	assert.Equal(t, "stacks", config.Stacks.BasePath)
+	assert.Equal(t, "{tenant}-{environment}-{stage}", config.Stacks.NamePattern)
+	assert.Equal(t, []string{"**/*"}, config.Stacks.IncludedPaths)
+	assert.Equal(t, false, config.Logs.Verbose)
+
+	// Migration is idempotent
+	changed, err = MigrateConfig(configFile)
+	assert.Nil(t, err)
+	assert.False(t, changed)
+
+	contentAfterSecondRun, err := os.ReadFile(configFile)
+	assert.Nil(t, err)
+	assert.Equal(t, string(content), string(contentAfterSecondRun))
+}
+
+func TestMigrateConfigKeepsNewKeys(t *testing.T) {
+	configFile := writeTestConfigFile(t, t.TempDir(), "atmos.yaml", `
+HelmfileDir: "old/helmfile"
+components:
+  helmfile:
+    base_path: "new/helmfile"
+`)
+
+	changed, err := MigrateConfig(configFile)
+	assert.Nil(t, err)
+	assert.True(t, changed)
+
+	content, err := os.ReadFile(configFile)
+	assert.Nil(t, err)
+
+	var config Configuration
+	err = yaml.Unmarshal(content, &config)
+	assert.Nil(t, err)
+	assert.Equal(t, "new/helmfile", config.Components.Helmfile.BasePath)
+	assert.NotContains(t, string(content), "HelmfileDir")
+}
+
+func TestMigrateConfigMissingFile(t *testing.T) {
+	_, err := MigrateConfig(path.Join(t.TempDir(), "missing.yaml"))
+	assert.NotNil(t, err)
+}
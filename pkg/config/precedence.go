@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	l "github.com/cloudposse/atmos/pkg/logger"
+	u "github.com/cloudposse/atmos/pkg/utils"
+	"github.com/pkg/errors"
+	"os"
+	"strings"
+)
+
+const (
+	configSourceSystem = "system"
+	configSourceHome   = "home"
+	configSourceCwd    = "cwd"
+	configSourceEnv    = "env"
+)
+
+// defaultConfigPrecedence is the default order of the CLI config sources (from lower to higher priority)
+var defaultConfigPrecedence = []string{
+	configSourceSystem,
+	configSourceHome,
+	configSourceCwd,
+	configSourceEnv,
+}
+
+// getConfigPrecedence returns the order of the CLI config sources (from lower to higher priority) from `ATMOS_CONFIG_PRECEDENCE` ENV var
+// (e.g. `system,home,env,cwd`), or the default order if the ENV var is not set
+func getConfigPrecedence() ([]string, error) {
+	configPrecedence := os.Getenv("ATMOS_CONFIG_PRECEDENCE")
+	if len(configPrecedence) == 0 {
+		return defaultConfigPrecedence, nil
+	}
+
+	l.Info("Found ENV var ATMOS_CONFIG_PRECEDENCE=%s", configPrecedence)
+	return parseConfigPrecedence(configPrecedence)
+}
+
+// parseConfigPrecedence parses the comma-separated list of the CLI config sources.
+// Each of the sources must be specified exactly once
+func parseConfigPrecedence(configPrecedence string) ([]string, error) {
+	var res []string
+
+	for _, source := range strings.Split(configPrecedence, ",") {
+		source = strings.TrimSpace(source)
+		if !u.SliceContainsString(defaultConfigPrecedence, source) {
+			return nil, errors.New(fmt.Sprintf("invalid config source '%s' in the ENV var ATMOS_CONFIG_PRECEDENCE. Supported sources are: %s",
+				source, strings.Join(defaultConfigPrecedence, ", ")))
+		}
+		if u.SliceContainsString(res, source) {
+			return nil, errors.New(fmt.Sprintf("the config source '%s' is specified more than once in the ENV var ATMOS_CONFIG_PRECEDENCE", source))
+		}
+		res = append(res, source)
+	}
+
+	for _, source := range defaultConfigPrecedence {
+		if !u.SliceContainsString(res, source) {
+			return nil, errors.New(fmt.Sprintf("the config source '%s' is missing in the ENV var ATMOS_CONFIG_PRECEDENCE. "+
+				"All the sources must be specified: %s", source, strings.Join(defaultConfigPrecedence, ", ")))
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	l "github.com/cloudposse/atmos/pkg/logger"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	remoteConfigURLEnvVar = "ATMOS_REMOTE_CONFIG_URL"
+	remoteConfigTimeout   = 10 * time.Second
+	// remoteConfigCacheTTL is the time the cached remote CLI config is used without fetching it again
+	remoteConfigCacheTTL = 5 * time.Minute
+)
+
+// remoteConfigHTTPClient is the HTTP client used to fetch the remote CLI config
+var remoteConfigHTTPClient = &http.Client{Timeout: remoteConfigTimeout}
+
+// getRemoteConfigURL returns the URL of the remote CLI config from the `ATMOS_REMOTE_CONFIG_URL` ENV var
+func getRemoteConfigURL() string {
+	remoteConfigURL := os.Getenv(remoteConfigURLEnvVar)
+	if len(remoteConfigURL) > 0 {
+		l.Debug("Found ENV var %s=%s", remoteConfigURLEnvVar, remoteConfigURL)
+	}
+	return remoteConfigURL
+}
+
+// constructRemoteConfigCacheFilePath returns the path to the file in the temp dir where the remote CLI config is cached.
+// The file name is the SHA256 hash of the URL
+func constructRemoteConfigCacheFilePath(remoteConfigURL string) string {
+	hash := sha256.Sum256([]byte(remoteConfigURL))
+	return filepath.Join(os.TempDir(), "atmos", "remote-config", hex.EncodeToString(hash[:])+".yaml")
+}
+
+// readRemoteConfigCache returns the cached remote CLI config if the cache file exists and is not older than `maxAge`.
+// If `maxAge` is zero, the cache file of any age is returned
+func readRemoteConfigCache(cacheFilePath string, maxAge time.Duration) ([]byte, bool) {
+	fileInfo, err := os.Stat(cacheFilePath)
+	if err != nil {
+		return nil, false
+	}
+	if maxAge > 0 && time.Since(fileInfo.ModTime()) > maxAge {
+		return nil, false
+	}
+
+	body, err := os.ReadFile(cacheFilePath)
+	if err != nil {
+		return nil, false
+	}
+	return body, true
+}
+
+// fetchRemoteConfig returns the remote CLI config. The config cached in the temp dir is used if it's not older than remoteConfigCacheTTL.
+// Otherwise, the config is downloaded, checked to be a valid YAML and cached.
+// If the config can't be downloaded because of a network error, the cached config of any age is used (if it exists)
+func fetchRemoteConfig(remoteConfigURL string) ([]byte, error) {
+	if !strings.HasPrefix(remoteConfigURL, "https://") {
+		return nil, errors.New(fmt.Sprintf("invalid remote config URL '%s' in the ENV var %s: only 'https://' URLs are supported",
+			remoteConfigURL, remoteConfigURLEnvVar))
+	}
+
+	cacheFilePath := constructRemoteConfigCacheFilePath(remoteConfigURL)
+	if body, ok := readRemoteConfigCache(cacheFilePath, remoteConfigCacheTTL); ok {
+		l.Debug("Using the remote config '%s' cached in %s", remoteConfigURL, cacheFilePath)
+		return body, nil
+	}
+
+	resp, err := remoteConfigHTTPClient.Get(remoteConfigURL)
+	if err != nil {
+		if body, ok := readRemoteConfigCache(cacheFilePath, 0); ok {
+			l.Warn("Error fetching the remote config '%s': %v. Using the cached config from %s", remoteConfigURL, err, cacheFilePath)
+			return body, nil
+		}
+		return nil, errors.New(fmt.Sprintf("error fetching the remote config '%s': %v", remoteConfigURL, err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(fmt.Sprintf("error fetching the remote config '%s': %s", remoteConfigURL, resp.Status))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("error reading the remote config '%s': %v", remoteConfigURL, err))
+	}
+
+	var remoteConfig map[interface{}]interface{}
+	err = yaml.Unmarshal(body, &remoteConfig)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("invalid remote config '%s': %v", remoteConfigURL, err))
+	}
+
+	err = os.MkdirAll(filepath.Dir(cacheFilePath), 0700)
+	if err != nil {
+		return nil, err
+	}
+	err = os.WriteFile(cacheFilePath, body, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Debug("Cached the remote config '%s' in %s", remoteConfigURL, cacheFilePath)
+
+	return body, nil
+}
+
+// processRemoteConfig fetches the remote CLI config and merges it into the config
+func processRemoteConfig(remoteConfigURL string, v *viper.Viper) error {
+	body, err := fetchRemoteConfig(remoteConfigURL)
+	if err != nil {
+		return err
+	}
+
+	err = v.MergeConfig(bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	l.DebugHighlight("Processed remote config %s", remoteConfigURL)
+
+	return nil
+}
+
+// isStrictModeEnabled checks if strict mode is enabled in the config merged so far or in the `ATMOS_SETTINGS_STRICT_MODE` ENV var
+func isStrictModeEnabled(v *viper.Viper) bool {
+	if strictMode, err := strconv.ParseBool(os.Getenv("ATMOS_SETTINGS_STRICT_MODE")); err == nil {
+		return strictMode
+	}
+	return v.GetBool("settings.strict_mode")
+}
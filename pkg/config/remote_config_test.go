@@ -0,0 +1,118 @@
+package config
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestInitConfigWithRemoteConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/atmos.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = fmt.Fprint(w, `
+stacks:
+  name_pattern: "{tenant}-{environment}-{stage}"
+components:
+  terraform:
+    base_path: "remote/terraform"
+`)
+	}))
+	t.Cleanup(server.Close)
+
+	httpClient := remoteConfigHTTPClient
+	remoteConfigHTTPClient = server.Client()
+	t.Cleanup(func() {
+		remoteConfigHTTPClient = httpClient
+	})
+
+	dir := t.TempDir()
+	cwd, err := os.Getwd()
+	assert.Nil(t, err)
+	err = os.Chdir(dir)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_ = os.Chdir(cwd)
+	})
+	t.Setenv("HOME", dir)
+	t.Setenv("TMPDIR", dir)
+
+	// The config in the current dir has higher priority than the remote config
+	writeTestConfigFile(t, dir, "atmos.yaml", `
+stacks:
+  name_pattern: "{tenant}-{stage}"
+`)
+
+	t.Setenv("ATMOS_REMOTE_CONFIG_URL", server.URL+"/atmos.yaml")
+	err = InitConfig()
+	assert.Nil(t, err)
+	assert.Equal(t, "{tenant}-{stage}", Config.Stacks.NamePattern)
+	assert.Equal(t, "remote/terraform", Config.Components.Terraform.BasePath)
+	assert.FileExists(t, constructRemoteConfigCacheFilePath(server.URL+"/atmos.yaml"))
+
+	// If the remote config can't be fetched, the layer is skipped
+	t.Setenv("ATMOS_REMOTE_CONFIG_URL", server.URL+"/missing.yaml")
+	err = InitConfig()
+	assert.Nil(t, err)
+	assert.Equal(t, "components/terraform", Config.Components.Terraform.BasePath)
+
+	// In strict mode, the error is fatal
+	t.Setenv("ATMOS_SETTINGS_STRICT_MODE", "true")
+	err = InitConfig()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "404 Not Found")
+}
+
+func TestFetchRemoteConfigUsesCache(t *testing.T) {
+	requests := 0
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = fmt.Fprintf(w, "stacks:\n  name_pattern: \"{stage}-%d\"\n", requests)
+	}))
+	t.Cleanup(server.Close)
+
+	httpClient := remoteConfigHTTPClient
+	remoteConfigHTTPClient = server.Client()
+	t.Cleanup(func() {
+		remoteConfigHTTPClient = httpClient
+	})
+	t.Setenv("TMPDIR", t.TempDir())
+
+	remoteConfigURL := server.URL + "/atmos.yaml"
+	body, err := fetchRemoteConfig(remoteConfigURL)
+	assert.Nil(t, err)
+	assert.Equal(t, "stacks:\n  name_pattern: \"{stage}-1\"\n", string(body))
+
+	// The cached config is used within the TTL
+	body, err = fetchRemoteConfig(remoteConfigURL)
+	assert.Nil(t, err)
+	assert.Equal(t, "stacks:\n  name_pattern: \"{stage}-1\"\n", string(body))
+	assert.Equal(t, 1, requests)
+
+	// The expired cache is refreshed
+	expired := time.Now().Add(-2 * remoteConfigCacheTTL)
+	cacheFilePath := constructRemoteConfigCacheFilePath(remoteConfigURL)
+	assert.Nil(t, os.Chtimes(cacheFilePath, expired, expired))
+	body, err = fetchRemoteConfig(remoteConfigURL)
+	assert.Nil(t, err)
+	assert.Equal(t, "stacks:\n  name_pattern: \"{stage}-2\"\n", string(body))
+	assert.Equal(t, 2, requests)
+
+	// The expired cache is used if the config can't be fetched because of a network error
+	assert.Nil(t, os.Chtimes(cacheFilePath, expired, expired))
+	server.Close()
+	body, err = fetchRemoteConfig(remoteConfigURL)
+	assert.Nil(t, err)
+	assert.Equal(t, "stacks:\n  name_pattern: \"{stage}-2\"\n", string(body))
+
+	// Without the cache, the network error is returned
+	assert.Nil(t, os.Remove(cacheFilePath))
+	_, err = fetchRemoteConfig(remoteConfigURL)
+	assert.NotNil(t, err)
+}
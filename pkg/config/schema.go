@@ -5,6 +5,9 @@ type Terraform struct {
 	ApplyAutoApprove        bool   `yaml:"apply_auto_approve" json:"apply_auto_approve" mapstructure:"apply_auto_approve"`
 	DeployRunInit           bool   `yaml:"deploy_run_init" json:"deploy_run_init" mapstructure:"deploy_run_init"`
 	AutoGenerateBackendFile bool   `yaml:"auto_generate_backend_file" json:"auto_generate_backend_file" mapstructure:"auto_generate_backend_file"`
+	Command                 string `yaml:"command" json:"command" mapstructure:"command"`
+	UseWorkspaces           bool   `yaml:"use_workspaces" json:"use_workspaces" mapstructure:"use_workspaces"`
+	DiscoverVarFiles        bool   `yaml:"discover_var_files" json:"discover_var_files" mapstructure:"discover_var_files"`
 }
 
 type Helmfile struct {
@@ -12,22 +15,29 @@ type Helmfile struct {
 	KubeconfigPath        string `yaml:"kubeconfig_path" json:"kubeconfig_path" mapstructure:"kubeconfig_path"`
 	HelmAwsProfilePattern string `yaml:"helm_aws_profile_pattern" json:"helm_aws_profile_pattern" mapstructure:"helm_aws_profile_pattern"`
 	ClusterNamePattern    string `yaml:"cluster_name_pattern" json:"cluster_name_pattern" mapstructure:"cluster_name_pattern"`
+	Command               string `yaml:"command" json:"command" mapstructure:"command"`
 }
 
 type Components struct {
 	Terraform Terraform
 	Helmfile  Helmfile
+	Allowlist []string `yaml:"allowlist" json:"allowlist" mapstructure:"allowlist"`
+	Denylist  []string `yaml:"denylist" json:"denylist" mapstructure:"denylist"`
 }
 
 type Stacks struct {
-	BasePath      string   `yaml:"base_path" json:"base_path" mapstructure:"base_path"`
-	IncludedPaths []string `yaml:"included_paths" json:"included_paths" mapstructure:"included_paths"`
-	ExcludedPaths []string `yaml:"excluded_paths" json:"excluded_paths" mapstructure:"excluded_paths"`
-	NamePattern   string   `yaml:"name_pattern" json:"name_pattern" mapstructure:"name_pattern"`
+	BasePath          string            `yaml:"base_path" json:"base_path" mapstructure:"base_path"`
+	IncludedPaths     []string          `yaml:"included_paths" json:"included_paths" mapstructure:"included_paths"`
+	IncludedPathsFile string            `yaml:"included_paths_file" json:"included_paths_file" mapstructure:"included_paths_file"`
+	ExcludedPaths     []string          `yaml:"excluded_paths" json:"excluded_paths" mapstructure:"excluded_paths"`
+	NamePattern       string            `yaml:"name_pattern" json:"name_pattern" mapstructure:"name_pattern"`
+	Aliases           map[string]string `yaml:"aliases" json:"aliases" mapstructure:"aliases"`
+	RequireStacks     bool              `yaml:"require_stacks" json:"require_stacks" mapstructure:"require_stacks"`
 }
 
 type Workflows struct {
 	BasePath string `yaml:"base_path" json:"base_path" mapstructure:"base_path"`
+	FailFast bool   `yaml:"fail_fast" json:"fail_fast" mapstructure:"fail_fast"`
 }
 
 type Logs struct {
@@ -35,12 +45,22 @@ type Logs struct {
 	Colors  bool `yaml:"colors" json:"colors" mapstructure:"colors"`
 }
 
+type Settings struct {
+	StrictMode        bool   `yaml:"strict_mode" json:"strict_mode" mapstructure:"strict_mode"`
+	ListMergeStrategy string `yaml:"list_merge_strategy" json:"list_merge_strategy" mapstructure:"list_merge_strategy"`
+}
+
 type Configuration struct {
-	BasePath   string `yaml:"base_path" json:"base_path" mapstructure:"base_path"`
-	Components Components
-	Stacks     Stacks
-	Workflows  Workflows
-	Logs       Logs
+	BasePath string `yaml:"base_path" json:"base_path" mapstructure:"base_path"`
+	// RequiredVersion is the version constraint (e.g. `>= 1.2.0`) for the atmos binary that can use the CLI config
+	RequiredVersion string `yaml:"required_version,omitempty" json:"required_version,omitempty" mapstructure:"required_version"`
+	Components      Components
+	Stacks          Stacks
+	Workflows       Workflows
+	Logs            Logs
+	Settings        Settings
+	// AppliedEnvOverrides contains the ENV vars (and their values) that override the CLI config settings
+	AppliedEnvOverrides map[string]string `yaml:"applied_env_overrides,omitempty" json:"applied_env_overrides,omitempty" mapstructure:"-"`
 }
 
 type ProcessedConfiguration struct {
@@ -72,10 +92,20 @@ type ArgsAndFlagsInfo struct {
 	ConfigDir               string
 	StacksDir               string
 	WorkflowsDir            string
+	StackNamePattern        string
 	BasePath                string
+	ConfigFiles             []string
 	DeployRunInit           string
 	AutoGenerateBackendFile string
 	UseTerraformPlan        bool
+	DryRun                  bool
+	CleanGenerated          bool
+	NoSchemaValidation      bool
+	RedirectStdErr          string
+	RedirectStdOut          string
+	Vars                    []string
+	VarsFile                string
+	LogsLevel               string
 	NeedHelp                bool
 }
 
@@ -105,11 +135,22 @@ type ConfigAndStacksInfo struct {
 	ConfigDir                 string
 	StacksDir                 string
 	WorkflowsDir              string
+	StackNamePattern          string
+	ConfigFiles               []string
 	Context                   Context
 	ContextPrefix             string
 	DeployRunInit             string
 	AutoGenerateBackendFile   string
 	UseTerraformPlan          bool
+	DryRun                    bool
+	CleanGenerated            bool
+	NoSchemaValidation        bool
+	RedirectStdErr            string
+	RedirectStdOut            string
+	Vars                      []string
+	VarsFile                  string
+	LogsLevel                 string
+	IncludeSourceMetadata     bool
 	ComponentInheritanceChain []string
 	NeedHelp                  bool
 	ComponentIsAbstract       bool
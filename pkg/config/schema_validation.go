@@ -0,0 +1,37 @@
+package config
+
+import (
+	_ "embed"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/xeipuuv/gojsonschema"
+	"strings"
+)
+
+// cliConfigSchema is the JSON Schema of the CLI config
+//
+//go:embed schemas/cli-config.schema.json
+var cliConfigSchema string
+
+// validateConfigWithSchema validates the merged CLI config settings against the embedded JSON Schema
+func validateConfigWithSchema(settings map[string]interface{}) error {
+	schemaLoader := gojsonschema.NewStringLoader(cliConfigSchema)
+	documentLoader := gojsonschema.NewGoLoader(settings)
+
+	result, err := gojsonschema.Validate(schemaLoader, documentLoader)
+	if err != nil {
+		return err
+	}
+
+	if result.Valid() {
+		return nil
+	}
+
+	var violations []string
+	for _, e := range result.Errors() {
+		violations = append(violations, fmt.Sprintf("- '%s': %s", e.Field(), e.Description()))
+	}
+
+	return errors.New(fmt.Sprintf("CLI config is invalid:\n%s\n\nUse '--no-schema-validation' flag to disable the CLI config schema validation",
+		strings.Join(violations, "\n")))
+}
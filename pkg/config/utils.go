@@ -5,15 +5,91 @@ import (
 	"fmt"
 	"github.com/bmatcuk/doublestar/v4"
 	g "github.com/cloudposse/atmos/pkg/globals"
+	l "github.com/cloudposse/atmos/pkg/logger"
+	m "github.com/cloudposse/atmos/pkg/merge"
 	s "github.com/cloudposse/atmos/pkg/stack"
 	u "github.com/cloudposse/atmos/pkg/utils"
 	"github.com/fatih/color"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 )
 
+// getStackConfigFilesMatchingPath returns the stack config files matching the provided path.
+// If the path is an existing file with an extension, it's returned as is without globbing.
+// Otherwise, the path is a glob (the default stack config file extension is added to the paths without an extension)
+func getStackConfigFilesMatchingPath(p string) ([]string, error) {
+	ext := filepath.Ext(p)
+
+	if ext != "" && u.FileExists(p) {
+		return []string{p}, nil
+	}
+
+	pathWithExt := p
+	if ext == "" {
+		pathWithExt = p + g.DefaultStackConfigFileExtension
+	}
+
+	// Find all matches in the glob
+	return s.GetGlobMatches(pathWithExt)
+}
+
+// getIncludedStackPaths returns the paths from the 'stacks.included_paths' config
+// with the paths from the 'stacks.included_paths_file' file (if specified) appended.
+// A relative path to the file is relative to the base path
+func getIncludedStackPaths() ([]string, error) {
+	includedPaths := append([]string{}, Config.Stacks.IncludedPaths...)
+
+	if len(Config.Stacks.IncludedPathsFile) == 0 {
+		return includedPaths, nil
+	}
+
+	includedPathsFile := Config.Stacks.IncludedPathsFile
+	if !filepath.IsAbs(includedPathsFile) {
+		includedPathsFile = filepath.Join(Config.BasePath, includedPathsFile)
+	}
+
+	pathsFromFile, err := readStackPathsFile(includedPathsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	includedPaths = append(includedPaths, pathsFromFile...)
+	if len(includedPaths) < 1 {
+		return nil, errors.New(fmt.Sprintf("no stack paths found in 'stacks.included_paths' config and in the file '%s'", includedPathsFile))
+	}
+
+	return includedPaths, nil
+}
+
+// readStackPathsFile reads the stack paths (globs) from the file, one path per line.
+// Blank lines and lines starting with '#' are ignored
+func readStackPathsFile(p string) ([]string, error) {
+	content, err := os.ReadFile(p)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("error reading the stack paths file '%s': %v", p, err))
+	}
+
+	var paths []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		paths = append(paths, line)
+	}
+
+	return paths, nil
+}
+
+// isGlobalDefaultsStackConfigFile checks if the file is the global defaults file in the root of the stacks base path.
+// The `_defaults.yaml` files in the subdirectories are processed as regular stack config files
+func isGlobalDefaultsStackConfigFile(p string) bool {
+	return filepath.Clean(p) == filepath.Join(ProcessedConfig.StacksBaseAbsolutePath, g.DefaultsStackConfigFileName)
+}
+
 // findAllStackConfigsInPathsForStack finds all stack config files in the paths specified by globs for the provided stack
 func findAllStackConfigsInPathsForStack(
 	stack string,
@@ -25,16 +101,8 @@ func findAllStackConfigsInPathsForStack(
 	var relativePaths []string
 
 	for _, p := range includeStackPaths {
-		pathWithExt := p
-
-		ext := filepath.Ext(p)
-		if ext == "" {
-			ext = g.DefaultStackConfigFileExtension
-			pathWithExt = p + ext
-		}
-
-		// Find all matches in the glob
-		matches, err := s.GetGlobMatches(pathWithExt)
+		// Find all stack config files matching the path
+		matches, err := getStackConfigFilesMatchingPath(p)
 		if err != nil {
 			return nil, nil, false, err
 		}
@@ -42,6 +110,11 @@ func findAllStackConfigsInPathsForStack(
 		// Exclude files that match any of the excludePaths
 		if matches != nil && len(matches) > 0 {
 			for _, matchedFileAbsolutePath := range matches {
+				// The global defaults file is merged into every stack and is not a stack itself
+				if isGlobalDefaultsStackConfigFile(matchedFileAbsolutePath) {
+					continue
+				}
+
 				matchedFileRelativePath := u.TrimBasePathFromPath(ProcessedConfig.StacksBaseAbsolutePath+"/", matchedFileAbsolutePath)
 
 				// Check if the provided stack matches a file in the config folders (excluding the files from `excludeStackPaths`)
@@ -98,16 +171,8 @@ func findAllStackConfigsInPaths(
 	var relativePaths []string
 
 	for _, p := range includeStackPaths {
-		pathWithExt := p
-
-		ext := filepath.Ext(p)
-		if ext == "" {
-			ext = g.DefaultStackConfigFileExtension
-			pathWithExt = p + ext
-		}
-
-		// Find all matches in the glob
-		matches, err := s.GetGlobMatches(pathWithExt)
+		// Find all stack config files matching the path
+		matches, err := getStackConfigFilesMatchingPath(p)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -115,6 +180,11 @@ func findAllStackConfigsInPaths(
 		// Exclude files that match any of the excludePaths
 		if matches != nil && len(matches) > 0 {
 			for _, matchedFileAbsolutePath := range matches {
+				// The global defaults file is merged into every stack and is not a stack itself
+				if isGlobalDefaultsStackConfigFile(matchedFileAbsolutePath) {
+					continue
+				}
+
 				matchedFileRelativePath := u.TrimBasePathFromPath(ProcessedConfig.StacksBaseAbsolutePath+"/", matchedFileAbsolutePath)
 				include := true
 
@@ -141,104 +211,283 @@ func findAllStackConfigsInPaths(
 	return absolutePaths, relativePaths, nil
 }
 
-func processEnvVars() error {
+// processEnvVars processes the ENV vars and overrides the corresponding CLI config settings.
+// The values are validated when applied (e.g. the stack name pattern tokens and the items in the comma-separated lists).
+// It returns a map of the found ENV vars and their values, or an error for an invalid ENV var value
+func processEnvVars() (map[string]string, error) {
+	appliedEnvOverrides := map[string]string{}
+
 	basePath := os.Getenv("ATMOS_BASE_PATH")
 	if len(basePath) > 0 {
-		color.Cyan("Found ENV var ATMOS_BASE_PATH=%s", basePath)
+		l.Info("Found ENV var ATMOS_BASE_PATH=%s", basePath)
+		appliedEnvOverrides["ATMOS_BASE_PATH"] = basePath
 		Config.BasePath = basePath
 	}
 
 	stacksBasePath := os.Getenv("ATMOS_STACKS_BASE_PATH")
 	if len(stacksBasePath) > 0 {
-		color.Cyan("Found ENV var ATMOS_STACKS_BASE_PATH=%s", stacksBasePath)
+		l.Info("Found ENV var ATMOS_STACKS_BASE_PATH=%s", stacksBasePath)
+		appliedEnvOverrides["ATMOS_STACKS_BASE_PATH"] = stacksBasePath
 		Config.Stacks.BasePath = stacksBasePath
 	}
 
 	stacksIncludedPaths := os.Getenv("ATMOS_STACKS_INCLUDED_PATHS")
 	if len(stacksIncludedPaths) > 0 {
-		color.Cyan("Found ENV var ATMOS_STACKS_INCLUDED_PATHS=%s", stacksIncludedPaths)
-		Config.Stacks.IncludedPaths = strings.Split(stacksIncludedPaths, ",")
+		l.Info("Found ENV var ATMOS_STACKS_INCLUDED_PATHS=%s", stacksIncludedPaths)
+		appliedEnvOverrides["ATMOS_STACKS_INCLUDED_PATHS"] = stacksIncludedPaths
+		list, err := parseEnvVarList("ATMOS_STACKS_INCLUDED_PATHS", stacksIncludedPaths)
+		if err != nil {
+			return nil, err
+		}
+		Config.Stacks.IncludedPaths = list
+	}
+
+	stacksIncludedPathsFile := os.Getenv("ATMOS_STACKS_INCLUDED_PATHS_FILE")
+	if len(stacksIncludedPathsFile) > 0 {
+		l.Info("Found ENV var ATMOS_STACKS_INCLUDED_PATHS_FILE=%s", stacksIncludedPathsFile)
+		appliedEnvOverrides["ATMOS_STACKS_INCLUDED_PATHS_FILE"] = stacksIncludedPathsFile
+		Config.Stacks.IncludedPathsFile = stacksIncludedPathsFile
 	}
 
 	stacksExcludedPaths := os.Getenv("ATMOS_STACKS_EXCLUDED_PATHS")
 	if len(stacksExcludedPaths) > 0 {
-		color.Cyan("Found ENV var ATMOS_STACKS_EXCLUDED_PATHS=%s", stacksExcludedPaths)
-		Config.Stacks.ExcludedPaths = strings.Split(stacksExcludedPaths, ",")
+		l.Info("Found ENV var ATMOS_STACKS_EXCLUDED_PATHS=%s", stacksExcludedPaths)
+		appliedEnvOverrides["ATMOS_STACKS_EXCLUDED_PATHS"] = stacksExcludedPaths
+		list, err := parseEnvVarList("ATMOS_STACKS_EXCLUDED_PATHS", stacksExcludedPaths)
+		if err != nil {
+			return nil, err
+		}
+		Config.Stacks.ExcludedPaths = list
 	}
 
 	stacksNamePattern := os.Getenv("ATMOS_STACKS_NAME_PATTERN")
 	if len(stacksNamePattern) > 0 {
-		color.Cyan("Found ENV var ATMOS_STACKS_NAME_PATTERN=%s", stacksNamePattern)
+		l.Info("Found ENV var ATMOS_STACKS_NAME_PATTERN=%s", stacksNamePattern)
+		appliedEnvOverrides["ATMOS_STACKS_NAME_PATTERN"] = stacksNamePattern
+		err := ValidateStackNamePattern(stacksNamePattern)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("invalid ENV var ATMOS_STACKS_NAME_PATTERN: %v", err))
+		}
 		Config.Stacks.NamePattern = stacksNamePattern
 	}
 
+	stacksAliases := os.Getenv("ATMOS_STACKS_ALIASES")
+	if len(stacksAliases) > 0 {
+		l.Info("Found ENV var ATMOS_STACKS_ALIASES=%s", stacksAliases)
+		appliedEnvOverrides["ATMOS_STACKS_ALIASES"] = stacksAliases
+		aliases := map[string]string{}
+		for _, alias := range strings.Split(stacksAliases, ",") {
+			aliasParts := strings.Split(alias, "=")
+			if len(aliasParts) != 2 || len(aliasParts[0]) == 0 || len(aliasParts[1]) == 0 {
+				return nil, errors.New(fmt.Sprintf("invalid stack alias '%s' in the ENV var ATMOS_STACKS_ALIASES. "+
+					"The aliases must be specified as a comma-separated list of 'alias=stack' pairs", alias))
+			}
+			aliases[aliasParts[0]] = aliasParts[1]
+		}
+		Config.Stacks.Aliases = aliases
+	}
+
+	stacksRequireStacks := os.Getenv("ATMOS_STACKS_REQUIRE_STACKS")
+	if len(stacksRequireStacks) > 0 {
+		l.Info("Found ENV var ATMOS_STACKS_REQUIRE_STACKS=%s", stacksRequireStacks)
+		appliedEnvOverrides["ATMOS_STACKS_REQUIRE_STACKS"] = stacksRequireStacks
+		stacksRequireStacksBool, err := strconv.ParseBool(stacksRequireStacks)
+		if err != nil {
+			return nil, err
+		}
+		Config.Stacks.RequireStacks = stacksRequireStacksBool
+	}
+
 	componentsTerraformBasePath := os.Getenv("ATMOS_COMPONENTS_TERRAFORM_BASE_PATH")
 	if len(componentsTerraformBasePath) > 0 {
-		color.Cyan("Found ENV var ATMOS_COMPONENTS_TERRAFORM_BASE_PATH=%s", componentsTerraformBasePath)
+		l.Info("Found ENV var ATMOS_COMPONENTS_TERRAFORM_BASE_PATH=%s", componentsTerraformBasePath)
+		appliedEnvOverrides["ATMOS_COMPONENTS_TERRAFORM_BASE_PATH"] = componentsTerraformBasePath
 		Config.Components.Terraform.BasePath = componentsTerraformBasePath
 	}
 
 	componentsTerraformApplyAutoApprove := os.Getenv("ATMOS_COMPONENTS_TERRAFORM_APPLY_AUTO_APPROVE")
 	if len(componentsTerraformApplyAutoApprove) > 0 {
-		color.Cyan("Found ENV var ATMOS_COMPONENTS_TERRAFORM_APPLY_AUTO_APPROVE=%s", componentsTerraformApplyAutoApprove)
+		l.Info("Found ENV var ATMOS_COMPONENTS_TERRAFORM_APPLY_AUTO_APPROVE=%s", componentsTerraformApplyAutoApprove)
+		appliedEnvOverrides["ATMOS_COMPONENTS_TERRAFORM_APPLY_AUTO_APPROVE"] = componentsTerraformApplyAutoApprove
 		applyAutoApproveBool, err := strconv.ParseBool(componentsTerraformApplyAutoApprove)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		Config.Components.Terraform.ApplyAutoApprove = applyAutoApproveBool
 	}
 
 	componentsTerraformDeployRunInit := os.Getenv("ATMOS_COMPONENTS_TERRAFORM_DEPLOY_RUN_INIT")
 	if len(componentsTerraformDeployRunInit) > 0 {
-		color.Cyan("Found ENV var ATMOS_COMPONENTS_TERRAFORM_DEPLOY_RUN_INIT=%s", componentsTerraformDeployRunInit)
+		l.Info("Found ENV var ATMOS_COMPONENTS_TERRAFORM_DEPLOY_RUN_INIT=%s", componentsTerraformDeployRunInit)
+		appliedEnvOverrides["ATMOS_COMPONENTS_TERRAFORM_DEPLOY_RUN_INIT"] = componentsTerraformDeployRunInit
 		deployRunInitBool, err := strconv.ParseBool(componentsTerraformDeployRunInit)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		Config.Components.Terraform.DeployRunInit = deployRunInitBool
 	}
 
 	componentsTerraformAutoGenerateBackendFile := os.Getenv("ATMOS_COMPONENTS_TERRAFORM_AUTO_GENERATE_BACKEND_FILE")
 	if len(componentsTerraformAutoGenerateBackendFile) > 0 {
-		color.Cyan("Found ENV var ATMOS_COMPONENTS_TERRAFORM_AUTO_GENERATE_BACKEND_FILE=%s", componentsTerraformAutoGenerateBackendFile)
+		l.Info("Found ENV var ATMOS_COMPONENTS_TERRAFORM_AUTO_GENERATE_BACKEND_FILE=%s", componentsTerraformAutoGenerateBackendFile)
+		appliedEnvOverrides["ATMOS_COMPONENTS_TERRAFORM_AUTO_GENERATE_BACKEND_FILE"] = componentsTerraformAutoGenerateBackendFile
 		componentsTerraformAutoGenerateBackendFileBool, err := strconv.ParseBool(componentsTerraformAutoGenerateBackendFile)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		Config.Components.Terraform.AutoGenerateBackendFile = componentsTerraformAutoGenerateBackendFileBool
 	}
 
+	componentsTerraformUseWorkspaces := os.Getenv("ATMOS_COMPONENTS_TERRAFORM_USE_WORKSPACES")
+	if len(componentsTerraformUseWorkspaces) > 0 {
+		l.Info("Found ENV var ATMOS_COMPONENTS_TERRAFORM_USE_WORKSPACES=%s", componentsTerraformUseWorkspaces)
+		appliedEnvOverrides["ATMOS_COMPONENTS_TERRAFORM_USE_WORKSPACES"] = componentsTerraformUseWorkspaces
+		componentsTerraformUseWorkspacesBool, err := strconv.ParseBool(componentsTerraformUseWorkspaces)
+		if err != nil {
+			return nil, err
+		}
+		Config.Components.Terraform.UseWorkspaces = componentsTerraformUseWorkspacesBool
+	}
+
+	componentsTerraformDiscoverVarFiles := os.Getenv("ATMOS_COMPONENTS_TERRAFORM_DISCOVER_VAR_FILES")
+	if len(componentsTerraformDiscoverVarFiles) > 0 {
+		l.Info("Found ENV var ATMOS_COMPONENTS_TERRAFORM_DISCOVER_VAR_FILES=%s", componentsTerraformDiscoverVarFiles)
+		appliedEnvOverrides["ATMOS_COMPONENTS_TERRAFORM_DISCOVER_VAR_FILES"] = componentsTerraformDiscoverVarFiles
+		componentsTerraformDiscoverVarFilesBool, err := strconv.ParseBool(componentsTerraformDiscoverVarFiles)
+		if err != nil {
+			return nil, err
+		}
+		Config.Components.Terraform.DiscoverVarFiles = componentsTerraformDiscoverVarFilesBool
+	}
+
+	terraformCommand := os.Getenv("ATMOS_TERRAFORM_COMMAND")
+	if len(terraformCommand) > 0 {
+		l.Info("Found ENV var ATMOS_TERRAFORM_COMMAND=%s", terraformCommand)
+		appliedEnvOverrides["ATMOS_TERRAFORM_COMMAND"] = terraformCommand
+		Config.Components.Terraform.Command = terraformCommand
+	}
+
+	componentsAllowlist := os.Getenv("ATMOS_COMPONENTS_ALLOWLIST")
+	if len(componentsAllowlist) > 0 {
+		l.Info("Found ENV var ATMOS_COMPONENTS_ALLOWLIST=%s", componentsAllowlist)
+		appliedEnvOverrides["ATMOS_COMPONENTS_ALLOWLIST"] = componentsAllowlist
+		list, err := parseEnvVarList("ATMOS_COMPONENTS_ALLOWLIST", componentsAllowlist)
+		if err != nil {
+			return nil, err
+		}
+		Config.Components.Allowlist = list
+	}
+
+	componentsDenylist := os.Getenv("ATMOS_COMPONENTS_DENYLIST")
+	if len(componentsDenylist) > 0 {
+		l.Info("Found ENV var ATMOS_COMPONENTS_DENYLIST=%s", componentsDenylist)
+		appliedEnvOverrides["ATMOS_COMPONENTS_DENYLIST"] = componentsDenylist
+		list, err := parseEnvVarList("ATMOS_COMPONENTS_DENYLIST", componentsDenylist)
+		if err != nil {
+			return nil, err
+		}
+		Config.Components.Denylist = list
+	}
+
 	componentsHelmfileBasePath := os.Getenv("ATMOS_COMPONENTS_HELMFILE_BASE_PATH")
 	if len(componentsHelmfileBasePath) > 0 {
-		color.Cyan("Found ENV var ATMOS_COMPONENTS_HELMFILE_BASE_PATH=%s", componentsHelmfileBasePath)
+		l.Info("Found ENV var ATMOS_COMPONENTS_HELMFILE_BASE_PATH=%s", componentsHelmfileBasePath)
+		appliedEnvOverrides["ATMOS_COMPONENTS_HELMFILE_BASE_PATH"] = componentsHelmfileBasePath
 		Config.Components.Helmfile.BasePath = componentsHelmfileBasePath
 	}
 
 	componentsHelmfileKubeconfigPath := os.Getenv("ATMOS_COMPONENTS_HELMFILE_KUBECONFIG_PATH")
 	if len(componentsHelmfileKubeconfigPath) > 0 {
-		color.Cyan("Found ENV var ATMOS_COMPONENTS_HELMFILE_KUBECONFIG_PATH=%s", componentsHelmfileKubeconfigPath)
+		l.Info("Found ENV var ATMOS_COMPONENTS_HELMFILE_KUBECONFIG_PATH=%s", componentsHelmfileKubeconfigPath)
+		appliedEnvOverrides["ATMOS_COMPONENTS_HELMFILE_KUBECONFIG_PATH"] = componentsHelmfileKubeconfigPath
 		Config.Components.Helmfile.KubeconfigPath = componentsHelmfileKubeconfigPath
 	}
 
 	componentsHelmfileHelmAwsProfilePattern := os.Getenv("ATMOS_COMPONENTS_HELMFILE_HELM_AWS_PROFILE_PATTERN")
 	if len(componentsHelmfileHelmAwsProfilePattern) > 0 {
-		color.Cyan("Found ENV var ATMOS_COMPONENTS_HELMFILE_HELM_AWS_PROFILE_PATTERN=%s", componentsHelmfileHelmAwsProfilePattern)
+		l.Info("Found ENV var ATMOS_COMPONENTS_HELMFILE_HELM_AWS_PROFILE_PATTERN=%s", componentsHelmfileHelmAwsProfilePattern)
+		appliedEnvOverrides["ATMOS_COMPONENTS_HELMFILE_HELM_AWS_PROFILE_PATTERN"] = componentsHelmfileHelmAwsProfilePattern
 		Config.Components.Helmfile.HelmAwsProfilePattern = componentsHelmfileHelmAwsProfilePattern
 	}
 
 	componentsHelmfileClusterNamePattern := os.Getenv("ATMOS_COMPONENTS_HELMFILE_CLUSTER_NAME_PATTERN")
 	if len(componentsHelmfileClusterNamePattern) > 0 {
-		color.Cyan("Found ENV var ATMOS_COMPONENTS_HELMFILE_CLUSTER_NAME_PATTERN=%s", componentsHelmfileClusterNamePattern)
+		l.Info("Found ENV var ATMOS_COMPONENTS_HELMFILE_CLUSTER_NAME_PATTERN=%s", componentsHelmfileClusterNamePattern)
+		appliedEnvOverrides["ATMOS_COMPONENTS_HELMFILE_CLUSTER_NAME_PATTERN"] = componentsHelmfileClusterNamePattern
 		Config.Components.Helmfile.ClusterNamePattern = componentsHelmfileClusterNamePattern
 	}
 
+	helmfileCommand := os.Getenv("ATMOS_HELMFILE_COMMAND")
+	if len(helmfileCommand) > 0 {
+		l.Info("Found ENV var ATMOS_HELMFILE_COMMAND=%s", helmfileCommand)
+		appliedEnvOverrides["ATMOS_HELMFILE_COMMAND"] = helmfileCommand
+		Config.Components.Helmfile.Command = helmfileCommand
+	}
+
 	workflowsBasePath := os.Getenv("ATMOS_WORKFLOWS_BASE_PATH")
 	if len(workflowsBasePath) > 0 {
-		color.Cyan("Found ENV var ATMOS_WORKFLOWS_BASE_PATH=%s", workflowsBasePath)
+		l.Info("Found ENV var ATMOS_WORKFLOWS_BASE_PATH=%s", workflowsBasePath)
+		appliedEnvOverrides["ATMOS_WORKFLOWS_BASE_PATH"] = workflowsBasePath
 		Config.Workflows.BasePath = workflowsBasePath
 	}
 
-	return nil
+	workflowsFailFast := os.Getenv("ATMOS_WORKFLOWS_FAIL_FAST")
+	if len(workflowsFailFast) > 0 {
+		l.Info("Found ENV var ATMOS_WORKFLOWS_FAIL_FAST=%s", workflowsFailFast)
+		appliedEnvOverrides["ATMOS_WORKFLOWS_FAIL_FAST"] = workflowsFailFast
+		workflowsFailFastBool, err := strconv.ParseBool(workflowsFailFast)
+		if err != nil {
+			return nil, err
+		}
+		Config.Workflows.FailFast = workflowsFailFastBool
+	}
+
+	settingsStrictMode := os.Getenv("ATMOS_SETTINGS_STRICT_MODE")
+	if len(settingsStrictMode) > 0 {
+		l.Info("Found ENV var ATMOS_SETTINGS_STRICT_MODE=%s", settingsStrictMode)
+		appliedEnvOverrides["ATMOS_SETTINGS_STRICT_MODE"] = settingsStrictMode
+		settingsStrictModeBool, err := strconv.ParseBool(settingsStrictMode)
+		if err != nil {
+			return nil, err
+		}
+		Config.Settings.StrictMode = settingsStrictModeBool
+	}
+
+	settingsListMergeStrategy := os.Getenv("ATMOS_SETTINGS_LIST_MERGE_STRATEGY")
+	if len(settingsListMergeStrategy) > 0 {
+		l.Info("Found ENV var ATMOS_SETTINGS_LIST_MERGE_STRATEGY=%s", settingsListMergeStrategy)
+		appliedEnvOverrides["ATMOS_SETTINGS_LIST_MERGE_STRATEGY"] = settingsListMergeStrategy
+		Config.Settings.ListMergeStrategy = settingsListMergeStrategy
+	}
+
+	return appliedEnvOverrides, nil
+}
+
+// normalizeConfigPaths converts the configured file and directory paths to the paths with the OS-specific separators,
+// and the configured stack path globs to the paths with forward slashes (as required by the globbing)
+func normalizeConfigPaths() {
+	Config.BasePath = normalizePath(Config.BasePath)
+	Config.Stacks.BasePath = normalizePath(Config.Stacks.BasePath)
+	Config.Stacks.IncludedPathsFile = normalizePath(Config.Stacks.IncludedPathsFile)
+	Config.Components.Terraform.BasePath = normalizePath(Config.Components.Terraform.BasePath)
+	Config.Components.Helmfile.BasePath = normalizePath(Config.Components.Helmfile.BasePath)
+	Config.Components.Helmfile.KubeconfigPath = normalizePath(Config.Components.Helmfile.KubeconfigPath)
+	Config.Workflows.BasePath = normalizePath(Config.Workflows.BasePath)
+
+	for i, p := range Config.Stacks.IncludedPaths {
+		Config.Stacks.IncludedPaths[i] = filepath.ToSlash(p)
+	}
+	for i, p := range Config.Stacks.ExcludedPaths {
+		Config.Stacks.ExcludedPaths[i] = filepath.ToSlash(p)
+	}
+}
+
+// normalizePath cleans the path and converts it to the path with the OS-specific separators. An empty path is returned as is
+func normalizePath(p string) string {
+	if len(p) == 0 {
+		return p
+	}
+	return filepath.Clean(filepath.FromSlash(p))
 }
 
 func checkConfig() error {
@@ -246,13 +495,127 @@ func checkConfig() error {
 		return errors.New("stack base path must be provided in 'stacks.base_path' config or ATMOS_STACKS_BASE_PATH' ENV variable")
 	}
 
-	if len(Config.Stacks.IncludedPaths) < 1 {
-		return errors.New("at least one path must be provided in 'stacks.included_paths' config or ATMOS_STACKS_INCLUDED_PATHS' ENV variable")
+	if len(Config.Stacks.IncludedPaths) < 1 && len(Config.Stacks.IncludedPathsFile) < 1 {
+		return errors.New("at least one path must be provided in 'stacks.included_paths' config or ATMOS_STACKS_INCLUDED_PATHS' ENV variable, " +
+			"or in the file specified in 'stacks.included_paths_file' config or 'ATMOS_STACKS_INCLUDED_PATHS_FILE' ENV variable")
+	}
+
+	if len(Config.Stacks.NamePattern) > 0 {
+		err := ValidateStackNamePattern(Config.Stacks.NamePattern)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(Config.Settings.ListMergeStrategy) > 0 {
+		err := m.ValidateListMergeStrategy(Config.Settings.ListMergeStrategy)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := checkStackAliasesConfig(Config.Stacks.Aliases)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// parseEnvVarList splits the comma-separated value of the ENV var into a list of trimmed items.
+// It returns an error if any of the items is empty
+func parseEnvVarList(name string, value string) ([]string, error) {
+	var res []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) == 0 {
+			return nil, errors.New(fmt.Sprintf("invalid ENV var %s='%s': the comma-separated list must not contain empty items", name, value))
+		}
+		res = append(res, item)
+	}
+	return res, nil
+}
+
+// ValidateStackNamePattern checks that the stack name pattern consists of the supported tokens separated by '-'
+func ValidateStackNamePattern(stackNamePattern string) error {
+	if len(stackNamePattern) == 0 {
+		return errors.New("stack name pattern must not be empty")
+	}
+
+	var foundTokens []string
+
+	for _, part := range strings.Split(stackNamePattern, "-") {
+		if !u.SliceContainsString(stackNamePatternTokens, part) {
+			return errors.New(fmt.Sprintf("invalid stack name pattern '%s': '%s' is not a supported token. "+
+				"The pattern must consist of the tokens %s separated by '-'",
+				stackNamePattern,
+				part,
+				strings.Join(stackNamePatternTokens, ", "),
+			))
+		}
+		if u.SliceContainsString(foundTokens, part) {
+			return errors.New(fmt.Sprintf("invalid stack name pattern '%s': the token '%s' is specified more than once", stackNamePattern, part))
+		}
+		foundTokens = append(foundTokens, part)
+	}
+
+	return nil
+}
+
+// ResolveStackAlias returns the stack name for the provided stack alias defined in 'stacks.aliases'.
+// If the provided stack is not an alias, it's returned unchanged
+func ResolveStackAlias(stack string) string {
+	if resolvedStack, ok := Config.Stacks.Aliases[stack]; ok {
+		return resolvedStack
+	}
+	return stack
+}
+
+// CheckStackAliases checks that the stack aliases defined in 'stacks.aliases' resolve to the provided (discovered) stacks,
+// and that the aliases don't have the same names as the stacks (the alias would hide the stack).
+// The aliases are checked in sorted order, and the errors for all the invalid aliases are returned
+func CheckStackAliases(stackNames []string) error {
+	var errs []string
+	for _, alias := range sortedStackAliases(Config.Stacks.Aliases) {
+		stack := Config.Stacks.Aliases[alias]
+		if alias != stack && u.SliceContainsString(stackNames, alias) {
+			errs = append(errs, fmt.Sprintf("the stack alias '%s' in 'stacks.aliases' has the same name as an existing stack", alias))
+		}
+		if !u.SliceContainsString(stackNames, stack) {
+			errs = append(errs, fmt.Sprintf("the stack alias '%s' in 'stacks.aliases' refers to the stack '%s' which does not exist", alias, stack))
+		}
 	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
 
+// checkStackAliasesConfig checks that the aliases and the stacks in 'stacks.aliases' are not empty, and that the aliases
+// don't refer to other aliases (the aliases are resolved only once)
+func checkStackAliasesConfig(aliases map[string]string) error {
+	for _, alias := range sortedStackAliases(aliases) {
+		stack := aliases[alias]
+		if len(strings.TrimSpace(alias)) == 0 || len(strings.TrimSpace(stack)) == 0 {
+			return errors.New(fmt.Sprintf("invalid stack alias '%s: %s' in 'stacks.aliases': the alias and the stack must not be empty", alias, stack))
+		}
+		if _, ok := aliases[stack]; ok && alias != stack {
+			return errors.New(fmt.Sprintf("the stack alias '%s' in 'stacks.aliases' refers to another alias '%s'. The aliases can't be chained", alias, stack))
+		}
+	}
 	return nil
 }
 
+// sortedStackAliases returns the sorted aliases from 'stacks.aliases'
+func sortedStackAliases(aliases map[string]string) []string {
+	var res []string
+	for alias := range aliases {
+		res = append(res, alias)
+	}
+	sort.Strings(res)
+	return res
+}
+
 func processCommandLineArgs(configAndStacksInfo ConfigAndStacksInfo) error {
 	if len(configAndStacksInfo.BasePath) > 0 {
 		Config.BasePath = configAndStacksInfo.BasePath
@@ -294,19 +657,54 @@ func processCommandLineArgs(configAndStacksInfo ConfigAndStacksInfo) error {
 		Config.Workflows.BasePath = configAndStacksInfo.WorkflowsDir
 		color.Cyan(fmt.Sprintf("Using command line argument '%s' as workflows directory", configAndStacksInfo.WorkflowsDir))
 	}
+	if len(configAndStacksInfo.StackNamePattern) > 0 {
+		Config.Stacks.NamePattern = configAndStacksInfo.StackNamePattern
+		color.Cyan(fmt.Sprintf("Using command line argument '%s' as stack name pattern", configAndStacksInfo.StackNamePattern))
+	}
 	return nil
 }
 
-func processLogsConfig() error {
+// processLogsConfig sets the threshold of the logger.
+// The logs level is taken from the `--logs-level` command-line flag, `ATMOS_LOGS_LEVEL` ENV var, or `ATMOS_LOGS_VERBOSE` ENV var
+// (`true` maps to `debug` for backward compatibility), in that order. The default logs level is `info`
+func processLogsConfig(logsLevelFlag string) error {
+	level := l.InfoLevel
+	var messages []string
+
 	logVerbose := os.Getenv("ATMOS_LOGS_VERBOSE")
 	if len(logVerbose) > 0 {
-		color.Cyan("Found ENV var ATMOS_LOGS_VERBOSE=%s", logVerbose)
+		messages = append(messages, fmt.Sprintf("Found ENV var ATMOS_LOGS_VERBOSE=%s", logVerbose))
 		logVerboseBool, err := strconv.ParseBool(logVerbose)
 		if err != nil {
 			return err
 		}
-		Config.Logs.Verbose = logVerboseBool
-		g.LogVerbose = logVerboseBool
+		if logVerboseBool {
+			level = l.DebugLevel
+		}
+	}
+
+	logsLevel := os.Getenv("ATMOS_LOGS_LEVEL")
+	if len(logsLevel) > 0 {
+		messages = append(messages, fmt.Sprintf("Found ENV var ATMOS_LOGS_LEVEL=%s", logsLevel))
+	}
+	if len(logsLevelFlag) > 0 {
+		logsLevel = logsLevelFlag
+	}
+	if len(logsLevel) > 0 {
+		var err error
+		level, err = l.ParseLevel(logsLevel)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Set the threshold before printing anything, so all the messages obey it
+	l.SetLevel(level)
+	g.LogVerbose = l.IsEnabled(l.DebugLevel)
+	Config.Logs.Verbose = g.LogVerbose
+
+	for _, message := range messages {
+		l.Info("%s", message)
 	}
 	return nil
 }
@@ -338,6 +736,19 @@ func GetContextFromVars(vars map[interface{}]interface{}) Context {
 	return context
 }
 
+// StackNameFunc calculates the logical stack name of a component in a stack from the stack config file name
+// (the path relative to the stacks base path without the extension) and the deep-merged component config in the stack.
+// All the functions that look up stacks by the logical names use it. It defaults to StackNameFromPattern,
+// and can be overridden by the programs embedding atmos to implement custom stack naming
+var StackNameFunc func(filePath string, contents map[string]interface{}) (string, error) = StackNameFromPattern
+
+// StackNameFromPattern calculates the logical stack name from the component's context (vars) using the stack name pattern
+func StackNameFromPattern(filePath string, contents map[string]interface{}) (string, error) {
+	vars, _ := contents["vars"].(map[interface{}]interface{})
+	context := GetContextFromVars(vars)
+	return GetContextPrefix(filePath, context, Config.Stacks.NamePattern)
+}
+
 // GetContextPrefix calculates context prefix
 func GetContextPrefix(stack string, context Context, stackNamePattern string) (string, error) {
 	if len(stackNamePattern) == 0 {
@@ -345,53 +756,49 @@ func GetContextPrefix(stack string, context Context, stackNamePattern string) (s
 			errors.New(fmt.Sprintf("Stack name pattern must be provided"))
 	}
 
-	contextPrefix := ""
-	stackNamePatternParts := strings.Split(stackNamePattern, "-")
+	tokens := map[string]string{
+		"namespace":   context.Namespace,
+		"tenant":      context.Tenant,
+		"environment": context.Environment,
+		"stage":       context.Stage,
+	}
 
-	for _, part := range stackNamePatternParts {
-		if part == "{tenant}" {
-			if len(context.Tenant) == 0 {
-				return "",
-					errors.New(fmt.Sprintf("The stack name pattern '%s' specifies 'tenant`, but the stack %s does not have a tenant defined",
-						stackNamePattern,
-						stack,
-					))
-			}
-			if len(contextPrefix) == 0 {
-				contextPrefix = context.Tenant
-			} else {
-				contextPrefix = contextPrefix + "-" + context.Tenant
-			}
-		} else if part == "{environment}" {
-			if len(context.Environment) == 0 {
-				return "",
-					errors.New(fmt.Sprintf("The stack name pattern '%s' specifies 'environment`, but the stack %s does not have an environment defined",
-						stackNamePattern,
-						stack,
-					))
-			}
-			if len(contextPrefix) == 0 {
-				contextPrefix = context.Environment
-			} else {
-				contextPrefix = contextPrefix + "-" + context.Environment
-			}
-		} else if part == "{stage}" {
-			if len(context.Stage) == 0 {
-				return "",
-					errors.New(fmt.Sprintf("The stack name pattern '%s' specifies 'stage`, but the stack %s does not have a stage defined",
-						Config.Stacks.NamePattern,
-						stack,
-					))
-			}
-			if len(contextPrefix) == 0 {
-				contextPrefix = context.Stage
-			} else {
-				contextPrefix = contextPrefix + "-" + context.Stage
+	contextPrefix, err := BuildStackName(stackNamePattern, tokens)
+	if err != nil {
+		return "", errors.New(fmt.Sprintf("%v in the stack %s", err, stack))
+	}
+
+	return contextPrefix, nil
+}
+
+// BuildStackName builds a stack name from the stack name pattern (e.g. '{tenant}-{environment}-{stage}')
+// by replacing each token in the pattern with the value for the token (e.g. 'tenant') from the provided map.
+// It returns an error if a token referenced in the pattern is not provided
+func BuildStackName(stackNamePattern string, tokens map[string]string) (string, error) {
+	if len(stackNamePattern) == 0 {
+		return "", errors.New("stack name pattern must be provided")
+	}
+
+	var stackNameParts []string
+
+	for _, part := range strings.Split(stackNamePattern, "-") {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			token := strings.TrimSuffix(strings.TrimPrefix(part, "{"), "}")
+			value, ok := tokens[token]
+			if !ok || len(value) == 0 {
+				return "", errors.New(fmt.Sprintf("the stack name pattern '%s' specifies '%s', but '%s' is not provided",
+					stackNamePattern,
+					part,
+					token,
+				))
 			}
+			stackNameParts = append(stackNameParts, value)
+		} else {
+			stackNameParts = append(stackNameParts, part)
 		}
 	}
 
-	return contextPrefix, nil
+	return strings.Join(stackNameParts, "-"), nil
 }
 
 // ReplaceContextTokens replaces tokens in the context pattern
@@ -0,0 +1,289 @@
+package config
+
+import (
+	m "github.com/cloudposse/atmos/pkg/merge"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestFindAllStackConfigsInPathsWithFilesAndGlobs(t *testing.T) {
+	stacksBasePath := t.TempDir()
+	err := os.MkdirAll(path.Join(stacksBasePath, "dev"), 0755)
+	assert.Nil(t, err)
+
+	for _, f := range []string{"prod.yaml", "staging.yaml", "dev/ue2.yaml", "dev/uw2.yaml"} {
+		writeTestConfigFile(t, stacksBasePath, f, "vars: {}\n")
+	}
+
+	ProcessedConfig.StacksBaseAbsolutePath = stacksBasePath
+
+	includeStackPaths := []string{
+		// Explicit file
+		path.Join(stacksBasePath, "prod.yaml"),
+		// Glob
+		path.Join(stacksBasePath, "dev/*"),
+	}
+
+	absolutePaths, relativePaths, err := findAllStackConfigsInPaths(includeStackPaths, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		path.Join(stacksBasePath, "prod.yaml"),
+		path.Join(stacksBasePath, "dev/ue2.yaml"),
+		path.Join(stacksBasePath, "dev/uw2.yaml"),
+	}, absolutePaths)
+	assert.Equal(t, []string{"prod.yaml", "dev/ue2.yaml", "dev/uw2.yaml"}, relativePaths)
+
+	// Explicit files are also checked against the excluded paths
+	excludeStackPaths := []string{path.Join(stacksBasePath, "prod.yaml")}
+	absolutePaths, _, err = findAllStackConfigsInPaths(includeStackPaths, excludeStackPaths)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		path.Join(stacksBasePath, "dev/ue2.yaml"),
+		path.Join(stacksBasePath, "dev/uw2.yaml"),
+	}, absolutePaths)
+
+	// The stack matches the explicit file
+	absolutePaths, relativePaths, stackIsPhysicalPath, err := findAllStackConfigsInPathsForStack("prod", includeStackPaths, nil)
+	assert.Nil(t, err)
+	assert.True(t, stackIsPhysicalPath)
+	assert.Equal(t, []string{path.Join(stacksBasePath, "prod.yaml")}, absolutePaths)
+	assert.Equal(t, []string{"prod.yaml"}, relativePaths)
+}
+
+func TestFindAllStackConfigsInPathsSkipsGlobalDefaults(t *testing.T) {
+	stacksBaseAbsolutePath := ProcessedConfig.StacksBaseAbsolutePath
+	t.Cleanup(func() {
+		ProcessedConfig.StacksBaseAbsolutePath = stacksBaseAbsolutePath
+	})
+
+	stacksBasePath := t.TempDir()
+	err := os.MkdirAll(path.Join(stacksBasePath, "dev"), 0755)
+	assert.Nil(t, err)
+
+	for _, f := range []string{"_defaults.yaml", "prod.yaml", "dev/_defaults.yaml"} {
+		writeTestConfigFile(t, stacksBasePath, f, "vars: {}\n")
+	}
+
+	ProcessedConfig.StacksBaseAbsolutePath = stacksBasePath
+
+	// Only the global defaults file in the root of the stacks base path is not a stack
+	_, relativePaths, err := findAllStackConfigsInPaths([]string{path.Join(stacksBasePath, "**/*")}, nil)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"prod.yaml", "dev/_defaults.yaml"}, relativePaths)
+}
+
+func TestValidateStackNamePattern(t *testing.T) {
+	assert.Nil(t, ValidateStackNamePattern("{tenant}-{environment}-{stage}"))
+	assert.Nil(t, ValidateStackNamePattern("{environment}-{stage}"))
+
+	assert.NotNil(t, ValidateStackNamePattern(""))
+	assert.NotNil(t, ValidateStackNamePattern("{tenant}-{region}"))
+	assert.NotNil(t, ValidateStackNamePattern("{tenant}_{stage}"))
+	assert.NotNil(t, ValidateStackNamePattern("{tenant}--{stage}"))
+	assert.NotNil(t, ValidateStackNamePattern("{stage}-{stage}"))
+}
+
+func TestBuildStackName(t *testing.T) {
+	tokens := map[string]string{
+		"namespace":   "eg",
+		"tenant":      "tenant1",
+		"environment": "ue2",
+		"stage":       "dev",
+	}
+
+	stackName, err := BuildStackName("{tenant}-{environment}-{stage}", tokens)
+	assert.Nil(t, err)
+	assert.Equal(t, "tenant1-ue2-dev", stackName)
+
+	stackName, err = BuildStackName("{namespace}-{tenant}-{environment}-{stage}", tokens)
+	assert.Nil(t, err)
+	assert.Equal(t, "eg-tenant1-ue2-dev", stackName)
+
+	stackName, err = BuildStackName("{environment}-{stage}", tokens)
+	assert.Nil(t, err)
+	assert.Equal(t, "ue2-dev", stackName)
+
+	_, err = BuildStackName("{tenant}-{environment}-{stage}", map[string]string{"environment": "ue2", "stage": "dev"})
+	assert.NotNil(t, err)
+	assert.Equal(t, "the stack name pattern '{tenant}-{environment}-{stage}' specifies '{tenant}', but 'tenant' is not provided", err.Error())
+
+	_, err = BuildStackName("", tokens)
+	assert.NotNil(t, err)
+}
+
+func TestGetIncludedStackPathsWithIncludedPathsFile(t *testing.T) {
+	basePath := t.TempDir()
+	writeTestConfigFile(t, basePath, "stacks.paths", `# Stack paths
+
+tenant1/**/*
+  # Indented comment
+  tenant2/**/*
+
+orgs/*.yaml
+`)
+
+	config := Config
+	t.Cleanup(func() { Config = config })
+
+	Config.BasePath = basePath
+	Config.Stacks.IncludedPaths = []string{"prod.yaml"}
+	Config.Stacks.IncludedPathsFile = "stacks.paths"
+
+	includedPaths, err := getIncludedStackPaths()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"prod.yaml", "tenant1/**/*", "tenant2/**/*", "orgs/*.yaml"}, includedPaths)
+	// The config is not modified
+	assert.Equal(t, []string{"prod.yaml"}, Config.Stacks.IncludedPaths)
+
+	Config.Stacks.IncludedPathsFile = "missing.paths"
+	_, err = getIncludedStackPaths()
+	assert.NotNil(t, err)
+}
+
+func TestProcessEnvVarsValidation(t *testing.T) {
+	config := Config
+	t.Cleanup(func() {
+		Config = config
+	})
+
+	t.Setenv("ATMOS_STACKS_INCLUDED_PATHS", " orgs/**/* , catalog/**/*")
+	t.Setenv("ATMOS_STACKS_NAME_PATTERN", "{tenant}-{stage}")
+	_, err := processEnvVars()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"orgs/**/*", "catalog/**/*"}, Config.Stacks.IncludedPaths)
+	assert.Equal(t, "{tenant}-{stage}", Config.Stacks.NamePattern)
+
+	t.Setenv("ATMOS_STACKS_NAME_PATTERN", "{tenant}-{region}")
+	_, err = processEnvVars()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "invalid ENV var ATMOS_STACKS_NAME_PATTERN")
+	assert.Contains(t, err.Error(), "'{region}' is not a supported token")
+
+	t.Setenv("ATMOS_STACKS_NAME_PATTERN", "")
+	t.Setenv("ATMOS_STACKS_EXCLUDED_PATHS", "**/_defaults.yaml,,")
+	_, err = processEnvVars()
+	assert.NotNil(t, err)
+	assert.Equal(t, "invalid ENV var ATMOS_STACKS_EXCLUDED_PATHS='**/_defaults.yaml,,': the comma-separated list must not contain empty items", err.Error())
+}
+
+func TestStackAliases(t *testing.T) {
+	config := Config
+	t.Cleanup(func() {
+		Config = config
+	})
+
+	stackNames := []string{"tenant1-ue2-dev", "tenant1-ue2-prod", "dev"}
+
+	tests := []struct {
+		name          string
+		aliases       map[string]string
+		stack         string
+		resolvedStack string
+		configErr     string
+		stacksErr     string
+	}{
+		{
+			name:          "alias is resolved",
+			aliases:       map[string]string{"prod": "tenant1-ue2-prod"},
+			stack:         "prod",
+			resolvedStack: "tenant1-ue2-prod",
+		},
+		{
+			name:          "stack that is not an alias is unchanged",
+			aliases:       map[string]string{"prod": "tenant1-ue2-prod"},
+			stack:         "tenant1-ue2-dev",
+			resolvedStack: "tenant1-ue2-dev",
+		},
+		{
+			name:          "alias has the same name as a stack",
+			aliases:       map[string]string{"dev": "tenant1-ue2-dev"},
+			stack:         "dev",
+			resolvedStack: "tenant1-ue2-dev",
+			stacksErr:     "the stack alias 'dev' in 'stacks.aliases' has the same name as an existing stack",
+		},
+		{
+			name:          "alias refers to an unknown stack",
+			aliases:       map[string]string{"staging": "tenant1-ue2-staging", "prod": "tenant1-ue2-prod", "qa": "tenant1-ue2-qa"},
+			stack:         "staging",
+			resolvedStack: "tenant1-ue2-staging",
+			// The errors are in the sorted order of the aliases
+			stacksErr: "the stack alias 'qa' in 'stacks.aliases' refers to the stack 'tenant1-ue2-qa' which does not exist\n" +
+				"the stack alias 'staging' in 'stacks.aliases' refers to the stack 'tenant1-ue2-staging' which does not exist",
+		},
+		{
+			name:          "alias refers to another alias",
+			aliases:       map[string]string{"p": "prod", "prod": "tenant1-ue2-prod"},
+			stack:         "p",
+			resolvedStack: "prod",
+			configErr:     "the stack alias 'p' in 'stacks.aliases' refers to another alias 'prod'. The aliases can't be chained",
+			stacksErr:     "the stack alias 'p' in 'stacks.aliases' refers to the stack 'prod' which does not exist",
+		},
+		{
+			name:          "empty stack",
+			aliases:       map[string]string{"prod": ""},
+			stack:         "prod",
+			resolvedStack: "",
+			configErr:     "invalid stack alias 'prod: ' in 'stacks.aliases': the alias and the stack must not be empty",
+			stacksErr:     "the stack alias 'prod' in 'stacks.aliases' refers to the stack '' which does not exist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Config.Stacks.Aliases = tt.aliases
+
+			assert.Equal(t, tt.resolvedStack, ResolveStackAlias(tt.stack))
+
+			err := checkStackAliasesConfig(tt.aliases)
+			if len(tt.configErr) > 0 {
+				assert.NotNil(t, err)
+				assert.Equal(t, tt.configErr, err.Error())
+			} else {
+				assert.Nil(t, err)
+			}
+
+			err = CheckStackAliases(stackNames)
+			if len(tt.stacksErr) > 0 {
+				assert.NotNil(t, err)
+				assert.Equal(t, tt.stacksErr, err.Error())
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckConfigWithInvalidStackAliases(t *testing.T) {
+	config := Config
+	t.Cleanup(func() {
+		Config = config
+	})
+
+	Config.Stacks.BasePath = "stacks"
+	Config.Stacks.IncludedPaths = []string{"**/*"}
+	Config.Stacks.Aliases = map[string]string{"prod": "tenant1-ue2-prod"}
+	assert.Nil(t, checkConfig())
+
+	Config.Stacks.Aliases = map[string]string{"p": "prod", "prod": "tenant1-ue2-prod"}
+	err := checkConfig()
+	assert.NotNil(t, err)
+	assert.Equal(t, "the stack alias 'p' in 'stacks.aliases' refers to another alias 'prod'. The aliases can't be chained", err.Error())
+}
+
+func TestCheckConfigListMergeStrategy(t *testing.T) {
+	config := Config
+	t.Cleanup(func() {
+		Config = config
+	})
+
+	Config.Stacks.BasePath = "stacks"
+	Config.Stacks.IncludedPaths = []string{"**/*"}
+	Config.Stacks.Aliases = nil
+	Config.Settings.ListMergeStrategy = m.ListMergeStrategyAppendUnique
+	assert.Nil(t, checkConfig())
+
+	Config.Settings.ListMergeStrategy = "prepend"
+	assert.NotNil(t, checkConfig())
+}
@@ -0,0 +1,33 @@
+//go:build windows
+// +build windows
+
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNormalizeConfigPathsOnWindows(t *testing.T) {
+	config := Config
+	t.Cleanup(func() { Config = config })
+
+	Config.BasePath = "./infra/"
+	Config.Stacks.BasePath = "stacks//orgs"
+	Config.Components.Terraform.BasePath = "components/terraform"
+	Config.Components.Helmfile.BasePath = `components\helmfile`
+	Config.Workflows.BasePath = ""
+	Config.Stacks.IncludedPaths = []string{`tenant1\**\*`, "tenant2/**/*"}
+	Config.Stacks.ExcludedPaths = []string{`**\*globals*`}
+
+	normalizeConfigPaths()
+
+	assert.Equal(t, "infra", Config.BasePath)
+	assert.Equal(t, `stacks\orgs`, Config.Stacks.BasePath)
+	assert.Equal(t, `components\terraform`, Config.Components.Terraform.BasePath)
+	assert.Equal(t, `components\helmfile`, Config.Components.Helmfile.BasePath)
+	assert.Equal(t, "", Config.Workflows.BasePath)
+	// The globs always use forward slashes
+	assert.Equal(t, []string{"tenant1/**/*", "tenant2/**/*"}, Config.Stacks.IncludedPaths)
+	assert.Equal(t, []string{"**/*globals*"}, Config.Stacks.ExcludedPaths)
+}
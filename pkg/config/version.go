@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+	"github.com/hashicorp/go-version"
+	"github.com/pkg/errors"
+)
+
+// AtmosVersion is the version of the running atmos binary used to check `required_version` in the CLI config
+var AtmosVersion = "0.0.1"
+
+// checkRequiredVersion checks if the provided atmos version satisfies the version constraint from `required_version` CLI config.
+// An empty constraint skips the check
+func checkRequiredVersion(requiredVersion string, atmosVersion string) error {
+	if len(requiredVersion) == 0 {
+		return nil
+	}
+
+	constraints, err := version.NewConstraint(requiredVersion)
+	if err != nil {
+		return errors.New(fmt.Sprintf("invalid 'required_version' constraint '%s' in the CLI config: %v", requiredVersion, err))
+	}
+
+	currentVersion, err := version.NewVersion(atmosVersion)
+	if err != nil {
+		return errors.New(fmt.Sprintf("invalid atmos version '%s': %v", atmosVersion, err))
+	}
+
+	if !constraints.Check(currentVersion) {
+		return errors.New(fmt.Sprintf("the CLI config requires atmos version '%s', but the running atmos version is '%s'. "+
+			"Please upgrade atmos", requiredVersion, atmosVersion))
+	}
+
+	return nil
+}
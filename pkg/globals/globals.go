@@ -6,6 +6,10 @@ const (
 	SystemDirConfigFilePath         = "/usr/local/etc/atmos"
 	WindowsAppDataEnvVar            = "LOCALAPPDATA"
 
+	// DefaultsStackConfigFileName is the name of the stack config file in the root of the stacks base path
+	// which is deep-merged as the lowest layer into every stack (global < stack < component)
+	DefaultsStackConfigFileName = "_defaults.yaml"
+
 	// GlobalOptionsFlag is a custom flag to specify helmfile `GLOBAL OPTIONS`
 	// https://github.com/roboll/helmfile#cli-reference
 	GlobalOptionsFlag = "--global-options"
@@ -17,10 +21,38 @@ const (
 	BasePathFlag     = "--base-path"
 	WorkflowDirFlag  = "--workflows-dir"
 
+	StackNamePatternFlag = "--stack-name-pattern"
+
+	// ConfigFlag specifies an additional CLI config file to merge on top of the discovered CLI config files (can be repeated)
+	ConfigFlag = "--config"
+
 	DeployRunInitFlag           = "--deploy-run-init"
 	AutoGenerateBackendFileFlag = "--auto-generate-backend-file"
 
 	FromPlanFlag = "--from-plan"
+	DryRunFlag   = "--dry-run"
+
+	// CleanGeneratedFlag removes the files generated by atmos (varfiles, backend files) after the command completes
+	CleanGeneratedFlag = "--clean-generated"
+
+	// NoSchemaValidationFlag disables the validation of the CLI config against the JSON Schema
+	NoSchemaValidationFlag = "--no-schema-validation"
+
+	// RedirectStdErrFlag specifies a file to write a copy of the stderr of the executed commands to (the stderr is still shown on the console)
+	RedirectStdErrFlag = "--redirect-stderr"
+
+	// RedirectStdOutFlag specifies a file to write a copy of the stdout of the executed commands to (the stdout is still shown on the console)
+	RedirectStdOutFlag = "--redirect-stdout"
+
+	// VarFlag overrides a variable of the component in the stack (e.g. `--var key=value`). The flag can be repeated
+	VarFlag = "--var"
+
+	// VarsFileFlag specifies a YAML or JSON file with the variables of the component.
+	// The variables override the variables from the stack config. Without stack config files (`stacks.require_stacks: false`), it's the only source of the variables
+	VarsFileFlag = "--vars-file"
+
+	// LogsLevelFlag sets the threshold of the logger (`trace`, `debug`, `info`, `warn` or `error`)
+	LogsLevelFlag = "--logs-level"
 
 	HelpFlag1 = "-h"
 	HelpFlag2 = "--help"
@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"fmt"
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// Level is the severity of a log message. Messages below the logger's threshold are not printed
+type Level int
+
+const (
+	TraceLevel Level = iota
+	DebugLevel
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+)
+
+// LevelNames are the names of the log levels (from the lowest to the highest severity)
+var LevelNames = []string{"trace", "debug", "info", "warn", "error"}
+
+var (
+	// Output is the writer the log messages are printed to
+	Output io.Writer = color.Output
+
+	threshold = InfoLevel
+
+	// colors can be disabled in the CLI config (`logs.colors: false`)
+	colors = true
+)
+
+// ParseLevel returns the log level for the provided name (e.g. `debug`)
+func ParseLevel(name string) (Level, error) {
+	for i, levelName := range LevelNames {
+		if strings.ToLower(name) == levelName {
+			return Level(i), nil
+		}
+	}
+	return InfoLevel, errors.New(fmt.Sprintf("invalid logs level '%s'. Allowed values are: %s", name, strings.Join(LevelNames, ", ")))
+}
+
+// String returns the name of the log level
+func (level Level) String() string {
+	if level < TraceLevel || level > ErrorLevel {
+		return fmt.Sprintf("Level(%d)", int(level))
+	}
+	return LevelNames[level]
+}
+
+// SetLevel sets the logger's threshold
+func SetLevel(level Level) {
+	threshold = level
+}
+
+// GetLevel returns the logger's threshold
+func GetLevel() Level {
+	return threshold
+}
+
+// IsEnabled checks if the messages with the provided level are printed
+func IsEnabled(level Level) bool {
+	return level >= threshold
+}
+
+// SetColors enables or disables the colors in the log messages.
+// Even if enabled, the colors are used only when the output is a terminal and `NO_COLOR` ENV var is not set
+func SetColors(enabled bool) {
+	colors = enabled
+}
+
+// UseColors checks if the colors should be used when writing to the provided writer.
+// The colors are not used if they are disabled, if `NO_COLOR` ENV var is set (https://no-color.org), or if the writer is not a terminal
+// (e.g. the output is piped or redirected to a file), so the scripts don't get the escape codes
+func UseColors(w io.Writer) bool {
+	if !colors || len(os.Getenv("NO_COLOR")) > 0 {
+		return false
+	}
+	// On Windows, the color writers wrap the console
+	if w == color.Output {
+		w = os.Stdout
+	} else if w == color.Error {
+		w = os.Stderr
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// Trace prints the message if the threshold is `trace`
+func Trace(format string, a ...interface{}) {
+	log(TraceLevel, nil, format, a...)
+}
+
+// Debug prints the message if the threshold is `debug` or lower
+func Debug(format string, a ...interface{}) {
+	log(DebugLevel, color.New(color.FgCyan), format, a...)
+}
+
+// DebugHighlight prints the message in green if the threshold is `debug` or lower (e.g. for the found config files)
+func DebugHighlight(format string, a ...interface{}) {
+	log(DebugLevel, color.New(color.FgGreen), format, a...)
+}
+
+// DebugDim prints the dimmed message if the threshold is `debug` or lower (e.g. for the missing optional config files)
+func DebugDim(format string, a ...interface{}) {
+	log(DebugLevel, color.New(color.Faint), format, a...)
+}
+
+// Info prints the message if the threshold is `info` or lower
+func Info(format string, a ...interface{}) {
+	log(InfoLevel, color.New(color.FgCyan), format, a...)
+}
+
+// Warn prints the message if the threshold is `warn` or lower
+func Warn(format string, a ...interface{}) {
+	log(WarnLevel, color.New(color.FgYellow), format, a...)
+}
+
+// Error prints the message if the threshold is `error` or lower
+func Error(format string, a ...interface{}) {
+	log(ErrorLevel, color.New(color.FgRed), format, a...)
+}
+
+func log(level Level, c *color.Color, format string, a ...interface{}) {
+	if !IsEnabled(level) {
+		return
+	}
+
+	message := fmt.Sprintf(format, a...)
+	if !strings.HasSuffix(message, "\n") {
+		message += "\n"
+	}
+
+	if c == nil || !UseColors(Output) {
+		_, _ = fmt.Fprint(Output, message)
+		return
+	}
+	c.EnableColor()
+	_, _ = c.Fprint(Output, message)
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"bytes"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func captureOutput(t *testing.T) *bytes.Buffer {
+	output := Output
+	level := GetLevel()
+	noColor := color.NoColor
+	t.Cleanup(func() {
+		Output = output
+		SetLevel(level)
+		color.NoColor = noColor
+	})
+
+	var buf bytes.Buffer
+	Output = &buf
+	color.NoColor = true
+	return &buf
+}
+
+func TestLoggerThreshold(t *testing.T) {
+	buf := captureOutput(t)
+
+	SetLevel(WarnLevel)
+	Trace("trace message")
+	Debug("debug message")
+	Info("info message")
+	Warn("warn message %d", 1)
+	Error("error message")
+	assert.Equal(t, "warn message 1\nerror message\n", buf.String())
+
+	buf.Reset()
+	SetLevel(TraceLevel)
+	Trace("trace message")
+	Debug("debug message\n")
+	assert.Equal(t, "trace message\ndebug message\n", buf.String())
+}
+
+func TestParseLevel(t *testing.T) {
+	for i, name := range LevelNames {
+		level, err := ParseLevel(name)
+		assert.Nil(t, err)
+		assert.Equal(t, Level(i), level)
+		assert.Equal(t, name, level.String())
+	}
+
+	level, err := ParseLevel("WARN")
+	assert.Nil(t, err)
+	assert.Equal(t, WarnLevel, level)
+
+	_, err = ParseLevel("verbose")
+	assert.NotNil(t, err)
+	assert.Equal(t, "invalid logs level 'verbose'. Allowed values are: trace, debug, info, warn, error", err.Error())
+}
+
+func TestLoggerPlainOutputWhenNotTerminal(t *testing.T) {
+	buf := captureOutput(t)
+	color.NoColor = false
+
+	SetLevel(DebugLevel)
+	DebugHighlight("Found config in %s", "atmos.yaml")
+	DebugDim("No config found in %s", "/usr/local/etc/atmos/atmos.yaml")
+	Error("error message")
+	assert.Equal(t, "Found config in atmos.yaml\nNo config found in /usr/local/etc/atmos/atmos.yaml\nerror message\n", buf.String())
+}
+
+func TestUseColors(t *testing.T) {
+	t.Cleanup(func() {
+		SetColors(true)
+	})
+
+	var buf bytes.Buffer
+	assert.False(t, UseColors(&buf))
+
+	// A pipe is not a terminal
+	r, w, err := os.Pipe()
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_ = r.Close()
+		_ = w.Close()
+	})
+	assert.False(t, UseColors(w))
+
+	t.Setenv("NO_COLOR", "1")
+	assert.False(t, UseColors(os.Stdout))
+
+	t.Setenv("NO_COLOR", "")
+	SetColors(false)
+	assert.False(t, UseColors(os.Stdout))
+}
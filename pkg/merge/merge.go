@@ -1,10 +1,26 @@
 package merge
 
 import (
+	"fmt"
 	"github.com/imdario/mergo"
+	"github.com/pkg/errors"
 	"gopkg.in/yaml.v2"
+	"reflect"
+	"strings"
 )
 
+const (
+	// ListMergeStrategyReplace replaces the lists from the previous maps with the lists from the next maps
+	ListMergeStrategyReplace = "replace"
+	// ListMergeStrategyAppend appends the lists from the next maps to the lists from the previous maps
+	ListMergeStrategyAppend = "append"
+	// ListMergeStrategyAppendUnique appends the lists and removes the duplicate items from the concatenated lists
+	ListMergeStrategyAppendUnique = "append-unique"
+)
+
+// ListMergeStrategies are the supported list merge strategies
+var ListMergeStrategies = []string{ListMergeStrategyReplace, ListMergeStrategyAppend, ListMergeStrategyAppendUnique}
+
 // MergeWithOptions takes a list of maps of interface and options as input and returns a single map with the merged contents
 func MergeWithOptions(inputs []map[interface{}]interface{}, appendSlice, sliceDeepCopy bool) (map[interface{}]interface{}, error) {
 	merged := map[interface{}]interface{}{}
@@ -46,7 +62,93 @@ func MergeWithOptions(inputs []map[interface{}]interface{}, appendSlice, sliceDe
 	return merged, nil
 }
 
-// Merge takes a list of maps of interface as input and returns a single map with the merged contents
+// MergeWithStrategy takes a list of maps of interface and a list merge strategy as input and returns a single map with the merged contents
+func MergeWithStrategy(inputs []map[interface{}]interface{}, listMergeStrategy string) (map[interface{}]interface{}, error) {
+	switch listMergeStrategy {
+	case ListMergeStrategyReplace, "":
+		return MergeWithOptions(inputs, false, false)
+	case ListMergeStrategyAppend:
+		return MergeWithOptions(inputs, true, false)
+	case ListMergeStrategyAppendUnique:
+		merged, err := MergeWithOptions(inputs, true, false)
+		if err != nil {
+			return nil, err
+		}
+		counts := map[string]int{}
+		for _, input := range inputs {
+			countLists(input, "", counts)
+		}
+		return uniqueConcatenatedListItems(merged, "", counts).(map[interface{}]interface{}), nil
+	default:
+		return nil, ValidateListMergeStrategy(listMergeStrategy)
+	}
+}
+
+// Merge takes a list of maps of interface as input and returns a single map with the merged contents.
+// The lists from the previous maps are replaced with the lists from the next maps
 func Merge(inputs []map[interface{}]interface{}) (map[interface{}]interface{}, error) {
-	return MergeWithOptions(inputs, false, false)
+	return MergeWithStrategy(inputs, ListMergeStrategyReplace)
+}
+
+// ValidateListMergeStrategy checks if the provided list merge strategy is supported
+func ValidateListMergeStrategy(listMergeStrategy string) error {
+	for _, strategy := range ListMergeStrategies {
+		if listMergeStrategy == strategy {
+			return nil
+		}
+	}
+	return errors.New(fmt.Sprintf("invalid list merge strategy '%s'. Supported strategies are: %s",
+		listMergeStrategy,
+		strings.Join(ListMergeStrategies, ", ")))
+}
+
+// countLists counts the inputs that have a list at each path in the provided value.
+// The lists in more than one input are concatenated by the `append` strategies
+func countLists(value interface{}, path string, counts map[string]int) {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		for k, item := range v {
+			countLists(item, path+"\x00"+fmt.Sprintf("%v", k), counts)
+		}
+	case map[string]interface{}:
+		for k, item := range v {
+			countLists(item, path+"\x00"+k, counts)
+		}
+	default:
+		if value != nil && reflect.TypeOf(value).Kind() == reflect.Slice {
+			counts[path]++
+		}
+	}
+}
+
+// uniqueConcatenatedListItems removes the duplicate items from the lists that were concatenated from more than one input.
+// The lists from a single input are not changed, even if they have duplicate items
+func uniqueConcatenatedListItems(value interface{}, path string, counts map[string]int) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		for k, item := range v {
+			v[k] = uniqueConcatenatedListItems(item, path+"\x00"+fmt.Sprintf("%v", k), counts)
+		}
+		return v
+	case []interface{}:
+		if counts[path] < 2 {
+			return v
+		}
+		result := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			found := false
+			for _, existing := range result {
+				if reflect.DeepEqual(existing, item) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				result = append(result, item)
+			}
+		}
+		return result
+	default:
+		return value
+	}
 }
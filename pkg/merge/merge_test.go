@@ -30,3 +30,70 @@ func TestMergeBasicOverride(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, expected, result)
 }
+
+func TestMergeListStrategies(t *testing.T) {
+	map1 := map[interface{}]interface{}{
+		"allowed_cidrs": []interface{}{"10.0.0.0/16", "10.1.0.0/16"},
+		"vpc": map[interface{}]interface{}{
+			"subnets": []interface{}{"a", "b"},
+		},
+	}
+	map2 := map[interface{}]interface{}{
+		"allowed_cidrs": []interface{}{"10.1.0.0/16", "10.2.0.0/16"},
+		"vpc": map[interface{}]interface{}{
+			"subnets": []interface{}{"b", "c"},
+		},
+	}
+	inputs := []map[interface{}]interface{}{map1, map2}
+
+	result, err := MergeWithStrategy(inputs, ListMergeStrategyReplace)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{"10.1.0.0/16", "10.2.0.0/16"}, result["allowed_cidrs"])
+	assert.Equal(t, []interface{}{"b", "c"}, result["vpc"].(map[interface{}]interface{})["subnets"])
+
+	result, err = MergeWithStrategy(inputs, ListMergeStrategyAppend)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{"10.0.0.0/16", "10.1.0.0/16", "10.1.0.0/16", "10.2.0.0/16"}, result["allowed_cidrs"])
+	assert.Equal(t, []interface{}{"a", "b", "b", "c"}, result["vpc"].(map[interface{}]interface{})["subnets"])
+
+	result, err = MergeWithStrategy(inputs, ListMergeStrategyAppendUnique)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{"10.0.0.0/16", "10.1.0.0/16", "10.2.0.0/16"}, result["allowed_cidrs"])
+	assert.Equal(t, []interface{}{"a", "b", "c"}, result["vpc"].(map[interface{}]interface{})["subnets"])
+
+	// The inputs are not modified
+	assert.Equal(t, []interface{}{"10.0.0.0/16", "10.1.0.0/16"}, map1["allowed_cidrs"])
+
+	_, err = MergeWithStrategy(inputs, "prepend")
+	assert.NotNil(t, err)
+}
+
+func TestMergeAppendUniqueKeepsSingleInputLists(t *testing.T) {
+	map1 := map[interface{}]interface{}{
+		"allowed_cidrs": []interface{}{"10.0.0.0/16", "10.0.0.0/16"},
+		"subnets":       []interface{}{"a", "b"},
+	}
+	map2 := map[interface{}]interface{}{
+		"subnets": []interface{}{"b", "c"},
+	}
+
+	result, err := MergeWithStrategy([]map[interface{}]interface{}{map1, map2}, ListMergeStrategyAppendUnique)
+	assert.Nil(t, err)
+	// The list from a single input keeps the duplicate items
+	assert.Equal(t, []interface{}{"10.0.0.0/16", "10.0.0.0/16"}, result["allowed_cidrs"])
+	assert.Equal(t, []interface{}{"a", "b", "c"}, result["subnets"])
+
+	// The result of the previous merge is not changed when it's merged again
+	result, err = MergeWithStrategy([]map[interface{}]interface{}{result}, ListMergeStrategyAppendUnique)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{"10.0.0.0/16", "10.0.0.0/16"}, result["allowed_cidrs"])
+}
+
+func TestMergeReplacesLists(t *testing.T) {
+	map1 := map[interface{}]interface{}{"list": []interface{}{"1"}}
+	map2 := map[interface{}]interface{}{"list": []interface{}{"2"}}
+
+	result, err := Merge([]map[interface{}]interface{}{map1, map2})
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{"2"}, result["list"])
+}
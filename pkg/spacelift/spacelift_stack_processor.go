@@ -37,11 +37,16 @@ func CreateSpaceliftStacks(
 			return nil, err
 		}
 
-		_, stacks, err := s.ProcessYAMLConfigFiles(
+		_, stacks, err := s.ProcessYAMLConfigFilesWithOptions(
 			c.ProcessedConfig.StacksBaseAbsolutePath,
 			c.ProcessedConfig.StackConfigFilesAbsolutePaths,
 			processStackDeps,
-			processComponentDeps)
+			processComponentDeps,
+			s.ProcessOptions{
+				ListMergeStrategy: c.Config.Settings.ListMergeStrategy,
+				TerraformCommand:  c.Config.Components.Terraform.Command,
+				HelmfileCommand:   c.Config.Components.Helmfile.Command,
+			})
 		if err != nil {
 			return nil, err
 		}
@@ -185,7 +190,7 @@ func LegacyTransformStackConfigToSpaceliftStacks(
 					} else {
 						workspace = fmt.Sprintf("%s-%s", stackName, component)
 					}
-					spaceliftConfig["workspace"] = strings.Replace(workspace, "/", "-", -1)
+					spaceliftConfig["workspace"] = getSpaceliftWorkspaceName(workspace)
 
 					// labels
 					var labels []string
@@ -404,7 +409,7 @@ func TransformStackConfigToSpaceliftStacks(
 					} else {
 						workspace = fmt.Sprintf("%s-%s", contextPrefix, component)
 					}
-					spaceliftConfig["workspace"] = strings.Replace(workspace, "/", "-", -1)
+					spaceliftConfig["workspace"] = getSpaceliftWorkspaceName(workspace)
 
 					// labels
 					labels := []string{}
@@ -481,3 +486,12 @@ func buildSpaceliftDependsOnStackName(
 
 	return spaceliftStackName, nil
 }
+
+// getSpaceliftWorkspaceName returns the terraform workspace name of the Spacelift stack.
+// The name is sanitized to the charset allowed by terraform only if `components.terraform.use_workspaces` is enabled in the CLI config
+func getSpaceliftWorkspaceName(workspace string) string {
+	if c.Config.Components.Terraform.UseWorkspaces {
+		return u.SanitizeTerraformWorkspaceName(workspace)
+	}
+	return workspace
+}
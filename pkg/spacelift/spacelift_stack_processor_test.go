@@ -4,6 +4,7 @@ import (
 	"gopkg.in/yaml.v2"
 	"testing"
 
+	c "github.com/cloudposse/atmos/pkg/config"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -125,3 +126,17 @@ func TestLegacySpaceliftStackProcessor(t *testing.T) {
 	assert.Nil(t, err)
 	t.Log(string(yamlSpaceliftStacks))
 }
+
+func TestGetSpaceliftWorkspaceName(t *testing.T) {
+	useWorkspaces := c.Config.Components.Terraform.UseWorkspaces
+	t.Cleanup(func() {
+		c.Config.Components.Terraform.UseWorkspaces = useWorkspaces
+	})
+
+	// The Spacelift workspace names are unchanged if terraform workspaces are not used
+	c.Config.Components.Terraform.UseWorkspaces = false
+	assert.Equal(t, "tenant1-ue2-dev-infra/vpc", getSpaceliftWorkspaceName("tenant1-ue2-dev-infra/vpc"))
+
+	c.Config.Components.Terraform.UseWorkspaces = true
+	assert.Equal(t, "tenant1-ue2-dev-infra-vpc", getSpaceliftWorkspaceName("tenant1-ue2-dev-infra/vpc"))
+}
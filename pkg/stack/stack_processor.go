@@ -22,6 +22,23 @@ var (
 	processYAMLConfigFilesLock = &sync.Mutex{}
 )
 
+// ProcessOptions are the options of the stack config processing
+type ProcessOptions struct {
+	// IncludeSourceMetadata adds the `_metadata` section with the stack config files that contributed the values to each component
+	IncludeSourceMetadata bool
+	// ListMergeStrategy is the strategy to deep-merge the lists (`replace` if not specified)
+	ListMergeStrategy string
+	// TerraformCommand is the default command for the terraform components that don't specify `command` (`terraform` if not specified)
+	TerraformCommand string
+	// HelmfileCommand is the default command for the helmfile components that don't specify `command` (`helmfile` if not specified)
+	HelmfileCommand string
+}
+
+// merge deep-merges the maps using the list merge strategy from the options
+func (options ProcessOptions) merge(inputs []map[interface{}]interface{}) (map[interface{}]interface{}, error) {
+	return m.MergeWithStrategy(inputs, options.ListMergeStrategy)
+}
+
 // ProcessYAMLConfigFiles takes a list of paths to YAML config files, processes and deep-merges all imports,
 // and returns a list of stack configs
 func ProcessYAMLConfigFiles(
@@ -30,6 +47,17 @@ func ProcessYAMLConfigFiles(
 	processStackDeps bool,
 	processComponentDeps bool) ([]string, map[string]interface{}, error) {
 
+	return ProcessYAMLConfigFilesWithOptions(basePath, filePaths, processStackDeps, processComponentDeps, ProcessOptions{})
+}
+
+// ProcessYAMLConfigFilesWithOptions is ProcessYAMLConfigFiles with the provided processing options
+func ProcessYAMLConfigFilesWithOptions(
+	basePath string,
+	filePaths []string,
+	processStackDeps bool,
+	processComponentDeps bool,
+	options ProcessOptions) ([]string, map[string]interface{}, error) {
+
 	count := len(filePaths)
 	listResult := make([]string, count)
 	mapResult := map[string]interface{}{}
@@ -46,7 +74,33 @@ func ProcessYAMLConfigFiles(
 				stackBasePath = path.Dir(p)
 			}
 
-			config, importsConfig, err := ProcessYAMLConfigFile(stackBasePath, p, map[string]map[interface{}]interface{}{})
+			importsConfig := map[string]map[interface{}]interface{}{}
+			var configs []map[interface{}]interface{}
+
+			var sources map[string]string
+			if options.IncludeSourceMetadata {
+				sources = map[string]string{}
+			}
+
+			// The global defaults file in the root of the stacks base path is deep-merged as the lowest layer into every stack
+			defaultsFilePath := path.Join(stackBasePath, g.DefaultsStackConfigFileName)
+			if defaultsFilePath != p && utils.FileExists(defaultsFilePath) {
+				defaultsConfig, _, err := ProcessYAMLConfigFileWithOptions(stackBasePath, defaultsFilePath, importsConfig, sources, options)
+				if err != nil {
+					errorResult = err
+					return
+				}
+				configs = append(configs, defaultsConfig)
+			}
+
+			stackConfig, importsConfig, err := ProcessYAMLConfigFileWithOptions(stackBasePath, p, importsConfig, sources, options)
+			if err != nil {
+				errorResult = err
+				return
+			}
+			configs = append(configs, stackConfig)
+
+			config, err := options.merge(configs)
 			if err != nil {
 				errorResult = err
 				return
@@ -71,14 +125,16 @@ func ProcessYAMLConfigFiles(
 			//	}
 			//}
 
-			finalConfig, err := ProcessConfig(stackBasePath,
+			finalConfig, err := ProcessConfigWithOptions(stackBasePath,
 				p,
 				config,
 				processStackDeps,
 				processComponentDeps,
 				"",
 				componentStackMap,
-				importsConfig)
+				importsConfig,
+				sources,
+				options)
 			if err != nil {
 				errorResult = err
 				return
@@ -123,6 +179,18 @@ func ProcessYAMLConfigFile(
 	filePath string,
 	importsConfig map[string]map[interface{}]interface{}) (map[interface{}]interface{}, map[string]map[interface{}]interface{}, error) {
 
+	return ProcessYAMLConfigFileWithOptions(basePath, filePath, importsConfig, nil, ProcessOptions{})
+}
+
+// ProcessYAMLConfigFileWithOptions is ProcessYAMLConfigFile with the provided processing options.
+// If `sources` is not nil, it records the config file that defines each value (the value path is the key in the map)
+func ProcessYAMLConfigFileWithOptions(
+	basePath string,
+	filePath string,
+	importsConfig map[string]map[interface{}]interface{},
+	sources map[string]string,
+	options ProcessOptions) (map[interface{}]interface{}, map[string]map[interface{}]interface{}, error) {
+
 	var configs []map[interface{}]interface{}
 
 	stackYamlConfig, err := getFileContent(filePath)
@@ -181,7 +249,7 @@ func ProcessYAMLConfigFile(
 			}
 
 			for _, importFile := range importMatches {
-				yamlConfig, _, err := ProcessYAMLConfigFile(basePath, importFile, importsConfig)
+				yamlConfig, _, err := ProcessYAMLConfigFileWithOptions(basePath, importFile, importsConfig, sources, options)
 				if err != nil {
 					return nil, nil, err
 				}
@@ -200,8 +268,13 @@ func ProcessYAMLConfigFile(
 
 	configs = append(configs, stackMapConfig)
 
+	// The values from the config file override the values from the imports
+	if sources != nil {
+		recordConfigSources(stackMapConfig, "", utils.TrimBasePathFromPath(basePath+"/", filePath), sources)
+	}
+
 	// Deep-merge the config file and the imports
-	result, err := m.Merge(configs)
+	result, err := options.merge(configs)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -222,6 +295,34 @@ func ProcessConfig(
 	importsConfig map[string]map[interface{}]interface{},
 ) (map[interface{}]interface{}, error) {
 
+	return ProcessConfigWithOptions(basePath,
+		stack,
+		config,
+		processStackDeps,
+		processComponentDeps,
+		componentTypeFilter,
+		componentStackMap,
+		importsConfig,
+		nil,
+		ProcessOptions{})
+}
+
+// ProcessConfigWithOptions is ProcessConfig with the provided processing options.
+// If `sources` is not nil (the config files that define the values, recorded by ProcessYAMLConfigFileWithOptions),
+// each component gets the `_metadata` section with the stack config files that contributed its values
+func ProcessConfigWithOptions(
+	basePath string,
+	stack string,
+	config map[interface{}]interface{},
+	processStackDeps bool,
+	processComponentDeps bool,
+	componentTypeFilter string,
+	componentStackMap map[string]map[string][]string,
+	importsConfig map[string]map[interface{}]interface{},
+	sources map[string]string,
+	options ProcessOptions,
+) (map[interface{}]interface{}, error) {
+
 	stackName := strings.TrimSuffix(
 		strings.TrimSuffix(
 			utils.TrimBasePathFromPath(basePath+"/", stack),
@@ -278,7 +379,7 @@ func ProcessConfig(
 		terraformVars = i.(map[interface{}]interface{})
 	}
 
-	globalAndTerraformVars, err := m.Merge([]map[interface{}]interface{}{globalVarsSection, terraformVars})
+	globalAndTerraformVars, err := options.merge([]map[interface{}]interface{}{globalVarsSection, terraformVars})
 	if err != nil {
 		return nil, err
 	}
@@ -287,7 +388,7 @@ func ProcessConfig(
 		terraformSettings = i.(map[interface{}]interface{})
 	}
 
-	globalAndTerraformSettings, err := m.Merge([]map[interface{}]interface{}{globalSettingsSection, terraformSettings})
+	globalAndTerraformSettings, err := options.merge([]map[interface{}]interface{}{globalSettingsSection, terraformSettings})
 	if err != nil {
 		return nil, err
 	}
@@ -296,7 +397,7 @@ func ProcessConfig(
 		terraformEnv = i.(map[interface{}]interface{})
 	}
 
-	globalAndTerraformEnv, err := m.Merge([]map[interface{}]interface{}{globalEnvSection, terraformEnv})
+	globalAndTerraformEnv, err := options.merge([]map[interface{}]interface{}{globalEnvSection, terraformEnv})
 	if err != nil {
 		return nil, err
 	}
@@ -326,7 +427,7 @@ func ProcessConfig(
 		helmfileVars = i.(map[interface{}]interface{})
 	}
 
-	globalAndHelmfileVars, err := m.Merge([]map[interface{}]interface{}{globalVarsSection, helmfileVars})
+	globalAndHelmfileVars, err := options.merge([]map[interface{}]interface{}{globalVarsSection, helmfileVars})
 	if err != nil {
 		return nil, err
 	}
@@ -335,7 +436,7 @@ func ProcessConfig(
 		helmfileSettings = i.(map[interface{}]interface{})
 	}
 
-	globalAndHelmfileSettings, err := m.Merge([]map[interface{}]interface{}{globalSettingsSection, helmfileSettings})
+	globalAndHelmfileSettings, err := options.merge([]map[interface{}]interface{}{globalSettingsSection, helmfileSettings})
 	if err != nil {
 		return nil, err
 	}
@@ -344,7 +445,7 @@ func ProcessConfig(
 		helmfileEnv = i.(map[interface{}]interface{})
 	}
 
-	globalAndHelmfileEnv, err := m.Merge([]map[interface{}]interface{}{globalEnvSection, helmfileEnv})
+	globalAndHelmfileEnv, err := options.merge([]map[interface{}]interface{}{globalEnvSection, helmfileEnv})
 	if err != nil {
 		return nil, err
 	}
@@ -423,7 +524,7 @@ func ProcessConfig(
 					baseComponentName = baseComponent
 
 					// Process the base components recursively to find `componentInheritanceChain`
-					err = processBaseComponentConfig(&baseComponentConfig, allTerraformComponentsMap, component, stack, baseComponentName)
+					err = processBaseComponentConfig(&baseComponentConfig, allTerraformComponentsMap, component, stack, baseComponentName, options)
 					if err != nil {
 						return nil, err
 					}
@@ -470,7 +571,7 @@ func ProcessConfig(
 						}
 
 						// Process the base components recursively to find `componentInheritanceChain`
-						err = processBaseComponentConfig(&baseComponentConfig, allTerraformComponentsMap, component, stack, base)
+						err = processBaseComponentConfig(&baseComponentConfig, allTerraformComponentsMap, component, stack, base, options)
 						if err != nil {
 							return nil, err
 						}
@@ -487,17 +588,17 @@ func ProcessConfig(
 					}
 				}
 
-				finalComponentVars, err := m.Merge([]map[interface{}]interface{}{globalAndTerraformVars, baseComponentVars, componentVars})
+				finalComponentVars, err := options.merge([]map[interface{}]interface{}{globalAndTerraformVars, baseComponentVars, componentVars})
 				if err != nil {
 					return nil, err
 				}
 
-				finalComponentSettings, err := m.Merge([]map[interface{}]interface{}{globalAndTerraformSettings, baseComponentSettings, componentSettings})
+				finalComponentSettings, err := options.merge([]map[interface{}]interface{}{globalAndTerraformSettings, baseComponentSettings, componentSettings})
 				if err != nil {
 					return nil, err
 				}
 
-				finalComponentEnv, err := m.Merge([]map[interface{}]interface{}{globalAndTerraformEnv, baseComponentEnv, componentEnv})
+				finalComponentEnv, err := options.merge([]map[interface{}]interface{}{globalAndTerraformEnv, baseComponentEnv, componentEnv})
 				if err != nil {
 					return nil, err
 				}
@@ -510,8 +611,12 @@ func ProcessConfig(
 				if len(componentBackendType) > 0 {
 					finalComponentBackendType = componentBackendType
 				}
+				// `settings.backend_type` overrides the backend type (e.g. for all the components in a stack)
+				if settingsBackendType, ok2 := finalComponentSettings["backend_type"].(string); ok2 && len(settingsBackendType) > 0 {
+					finalComponentBackendType = settingsBackendType
+				}
 
-				finalComponentBackendSection, err := m.Merge([]map[interface{}]interface{}{globalBackendSection,
+				finalComponentBackendSection, err := options.merge([]map[interface{}]interface{}{globalBackendSection,
 					baseComponentBackendSection,
 					componentBackendSection})
 				if err != nil {
@@ -569,7 +674,7 @@ func ProcessConfig(
 					finalComponentRemoteStateBackendType = componentRemoteStateBackendType
 				}
 
-				finalComponentRemoteStateBackendSection, err := m.Merge([]map[interface{}]interface{}{globalRemoteStateBackendSection,
+				finalComponentRemoteStateBackendSection, err := options.merge([]map[interface{}]interface{}{globalRemoteStateBackendSection,
 					baseComponentRemoteStateBackendSection,
 					componentRemoteStateBackendSection})
 				if err != nil {
@@ -578,7 +683,7 @@ func ProcessConfig(
 
 				// Merge `backend` and `remote_state_backend` sections
 				// This will allow keeping `remote_state_backend` section DRY
-				finalComponentRemoteStateBackendSectionMerged, err := m.Merge([]map[interface{}]interface{}{finalComponentBackendSection,
+				finalComponentRemoteStateBackendSectionMerged, err := options.merge([]map[interface{}]interface{}{finalComponentBackendSection,
 					finalComponentRemoteStateBackendSection})
 				if err != nil {
 					return nil, err
@@ -591,6 +696,9 @@ func ProcessConfig(
 
 				// Final binary to execute
 				finalComponentTerraformCommand := "terraform"
+				if len(options.TerraformCommand) > 0 {
+					finalComponentTerraformCommand = options.TerraformCommand
+				}
 				if len(baseComponentTerraformCommand) > 0 {
 					finalComponentTerraformCommand = baseComponentTerraformCommand
 				}
@@ -658,6 +766,10 @@ func ProcessConfig(
 					comp["deps"] = []string{}
 				}
 
+				if sources != nil {
+					comp["_metadata"] = getComponentSourceMetadata("terraform", component, componentInheritanceChain, comp, sources)
+				}
+
 				terraformComponents[component] = comp
 			}
 		}
@@ -713,7 +825,7 @@ func ProcessConfig(
 					baseComponentName = baseComponent
 
 					// Process the base components recursively to find `componentInheritanceChain`
-					err = processBaseComponentConfig(&baseComponentConfig, allHelmfileComponentsMap, component, stack, baseComponentName)
+					err = processBaseComponentConfig(&baseComponentConfig, allHelmfileComponentsMap, component, stack, baseComponentName, options)
 					if err != nil {
 						return nil, err
 					}
@@ -756,7 +868,7 @@ func ProcessConfig(
 						}
 
 						// Process the base components recursively to find `componentInheritanceChain`
-						err = processBaseComponentConfig(&baseComponentConfig, allHelmfileComponentsMap, component, stack, base)
+						err = processBaseComponentConfig(&baseComponentConfig, allHelmfileComponentsMap, component, stack, base, options)
 						if err != nil {
 							return nil, err
 						}
@@ -770,23 +882,26 @@ func ProcessConfig(
 					}
 				}
 
-				finalComponentVars, err := m.Merge([]map[interface{}]interface{}{globalAndHelmfileVars, baseComponentVars, componentVars})
+				finalComponentVars, err := options.merge([]map[interface{}]interface{}{globalAndHelmfileVars, baseComponentVars, componentVars})
 				if err != nil {
 					return nil, err
 				}
 
-				finalComponentSettings, err := m.Merge([]map[interface{}]interface{}{globalAndHelmfileSettings, baseComponentSettings, componentSettings})
+				finalComponentSettings, err := options.merge([]map[interface{}]interface{}{globalAndHelmfileSettings, baseComponentSettings, componentSettings})
 				if err != nil {
 					return nil, err
 				}
 
-				finalComponentEnv, err := m.Merge([]map[interface{}]interface{}{globalAndHelmfileEnv, baseComponentEnv, componentEnv})
+				finalComponentEnv, err := options.merge([]map[interface{}]interface{}{globalAndHelmfileEnv, baseComponentEnv, componentEnv})
 				if err != nil {
 					return nil, err
 				}
 
 				// Final binary to execute
 				finalComponentHelmfileCommand := "helmfile"
+				if len(options.HelmfileCommand) > 0 {
+					finalComponentHelmfileCommand = options.HelmfileCommand
+				}
 				if len(baseComponentHelmfileCommand) > 0 {
 					finalComponentHelmfileCommand = baseComponentHelmfileCommand
 				}
@@ -828,6 +943,10 @@ func ProcessConfig(
 					comp["deps"] = []string{}
 				}
 
+				if sources != nil {
+					comp["_metadata"] = getComponentSourceMetadata("helmfile", component, componentInheritanceChain, comp, sources)
+				}
+
 				helmfileComponents[component] = comp
 			}
 		}
@@ -862,7 +981,8 @@ func processBaseComponentConfig(
 	allComponentsMap map[interface{}]interface{},
 	component string,
 	stack string,
-	baseComponent string) error {
+	baseComponent string,
+	options ProcessOptions) error {
 
 	if component == baseComponent {
 		return nil
@@ -889,6 +1009,7 @@ func processBaseComponentConfig(
 				baseComponent,
 				stack,
 				baseComponentOfBaseComponent.(string),
+				options,
 			)
 
 			if err != nil {
@@ -934,21 +1055,21 @@ func processBaseComponentConfig(
 		}
 
 		// Base component `vars`
-		merged, err := m.Merge([]map[interface{}]interface{}{baseComponentConfig.BaseComponentVars, baseComponentVars})
+		merged, err := options.merge([]map[interface{}]interface{}{baseComponentConfig.BaseComponentVars, baseComponentVars})
 		if err != nil {
 			return err
 		}
 		baseComponentConfig.BaseComponentVars = merged
 
 		// Base component `settings`
-		merged, err = m.Merge([]map[interface{}]interface{}{baseComponentConfig.BaseComponentSettings, baseComponentSettings})
+		merged, err = options.merge([]map[interface{}]interface{}{baseComponentConfig.BaseComponentSettings, baseComponentSettings})
 		if err != nil {
 			return err
 		}
 		baseComponentConfig.BaseComponentSettings = merged
 
 		// Base component `env`
-		merged, err = m.Merge([]map[interface{}]interface{}{baseComponentConfig.BaseComponentEnv, baseComponentEnv})
+		merged, err = options.merge([]map[interface{}]interface{}{baseComponentConfig.BaseComponentEnv, baseComponentEnv})
 		if err != nil {
 			return err
 		}
@@ -958,7 +1079,7 @@ func processBaseComponentConfig(
 		baseComponentConfig.BaseComponentBackendType = baseComponentBackendType
 
 		// Base component `backend`
-		merged, err = m.Merge([]map[interface{}]interface{}{baseComponentConfig.BaseComponentBackendSection, baseComponentBackendSection})
+		merged, err = options.merge([]map[interface{}]interface{}{baseComponentConfig.BaseComponentBackendSection, baseComponentBackendSection})
 		if err != nil {
 			return err
 		}
@@ -967,7 +1088,7 @@ func processBaseComponentConfig(
 		// Base component `backend_type`
 		baseComponentConfig.BaseComponentRemoteStateBackendType = baseComponentRemoteStateBackendType
 
-		merged, err = m.Merge([]map[interface{}]interface{}{baseComponentConfig.BaseComponentRemoteStateBackendSection, baseComponentRemoteStateBackendSection})
+		merged, err = options.merge([]map[interface{}]interface{}{baseComponentConfig.BaseComponentRemoteStateBackendSection, baseComponentRemoteStateBackendSection})
 		if err != nil {
 			return err
 		}
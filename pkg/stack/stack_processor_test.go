@@ -5,6 +5,8 @@ import (
 	u "github.com/cloudposse/atmos/pkg/utils"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v2"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -168,3 +170,179 @@ func TestStackProcessor(t *testing.T) {
 	assert.Nil(t, err)
 	t.Log(string(yamlConfig))
 }
+
+func TestStackProcessorGlobalDefaults(t *testing.T) {
+	basePath := t.TempDir()
+
+	defaults := `
+vars:
+  region: us-east-2
+  enabled: false
+settings:
+  spacelift:
+    workspace_enabled: false
+`
+	stack := `
+vars:
+  stage: dev
+settings:
+  spacelift:
+    workspace_enabled: true
+components:
+  terraform:
+    vpc:
+      vars:
+        enabled: true
+    eks:
+      vars: {}
+`
+	err := os.WriteFile(filepath.Join(basePath, "_defaults.yaml"), []byte(defaults), 0644)
+	assert.Nil(t, err)
+	err = os.WriteFile(filepath.Join(basePath, "dev.yaml"), []byte(stack), 0644)
+	assert.Nil(t, err)
+
+	_, mapResult, err := ProcessYAMLConfigFiles(basePath, []string{filepath.Join(basePath, "dev.yaml")}, false, false)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(mapResult))
+
+	components := mapResult["dev"].(map[interface{}]interface{})["components"].(map[string]interface{})
+	terraformComponents := components["terraform"].(map[string]interface{})
+
+	vpcComponent := terraformComponents["vpc"].(map[string]interface{})
+	vpcVars := vpcComponent["vars"].(map[interface{}]interface{})
+	vpcSettings := vpcComponent["settings"].(map[interface{}]interface{})
+	// The component-level var overrides the global default
+	assert.Equal(t, true, vpcVars["enabled"])
+	// The global default is inherited when not overridden
+	assert.Equal(t, "us-east-2", vpcVars["region"])
+	assert.Equal(t, "dev", vpcVars["stage"])
+	// The stack-level setting overrides the global default
+	assert.Equal(t, true, vpcSettings["spacelift"].(map[interface{}]interface{})["workspace_enabled"])
+
+	eksComponent := terraformComponents["eks"].(map[string]interface{})
+	eksVars := eksComponent["vars"].(map[interface{}]interface{})
+	assert.Equal(t, false, eksVars["enabled"])
+}
+
+func TestStackProcessorBackendTypeOverrideInSettings(t *testing.T) {
+	basePath := t.TempDir()
+
+	stack := `
+settings:
+  backend_type: gcs
+terraform:
+  backend_type: s3
+  backend:
+    s3:
+      bucket: eg-tfstate
+    gcs:
+      bucket: eg-gcs-tfstate
+components:
+  terraform:
+    vpc:
+      vars: {}
+      backend:
+        gcs:
+          prefix: vpc
+`
+	err := os.WriteFile(filepath.Join(basePath, "dev.yaml"), []byte(stack), 0644)
+	assert.Nil(t, err)
+
+	_, mapResult, err := ProcessYAMLConfigFiles(basePath, []string{filepath.Join(basePath, "dev.yaml")}, false, false)
+	assert.Nil(t, err)
+
+	components := mapResult["dev"].(map[interface{}]interface{})["components"].(map[string]interface{})
+	vpcComponent := components["terraform"].(map[string]interface{})["vpc"].(map[string]interface{})
+	assert.Equal(t, "gcs", vpcComponent["backend_type"])
+	// The backend config for the type is deep-merged from the global and component backend sections
+	assert.Equal(t, map[interface{}]interface{}{"bucket": "eg-gcs-tfstate", "prefix": "vpc"}, vpcComponent["backend"])
+}
+
+func TestStackProcessorSourceMetadata(t *testing.T) {
+	basePath := t.TempDir()
+
+	defaults := `
+vars:
+  cidr: 10.0.0.0/16
+`
+	catalog := `
+components:
+  terraform:
+    vpc-defaults:
+      vars:
+        nat_gateway_enabled: true
+`
+	stack := `
+import:
+  - catalog/vpc
+vars:
+  stage: prod
+components:
+  terraform:
+    vpc:
+      component: vpc-defaults
+      vars:
+        name: prod-vpc
+`
+	err := os.MkdirAll(filepath.Join(basePath, "catalog"), 0755)
+	assert.Nil(t, err)
+	err = os.WriteFile(filepath.Join(basePath, "_defaults.yaml"), []byte(defaults), 0644)
+	assert.Nil(t, err)
+	err = os.WriteFile(filepath.Join(basePath, "catalog", "vpc.yaml"), []byte(catalog), 0644)
+	assert.Nil(t, err)
+	err = os.WriteFile(filepath.Join(basePath, "prod.yaml"), []byte(stack), 0644)
+	assert.Nil(t, err)
+
+	_, mapResult, err := ProcessYAMLConfigFilesWithOptions(basePath, []string{filepath.Join(basePath, "prod.yaml")}, false, false, ProcessOptions{IncludeSourceMetadata: true})
+	assert.Nil(t, err)
+
+	components := mapResult["prod"].(map[interface{}]interface{})["components"].(map[string]interface{})
+	vpcComponent := components["terraform"].(map[string]interface{})["vpc"].(map[string]interface{})
+	metadata := vpcComponent["_metadata"].(map[string]interface{})
+	sources := metadata["sources"].(map[string]string)
+	assert.Equal(t, "_defaults.yaml", sources["vars.cidr"])
+	assert.Equal(t, "catalog/vpc.yaml", sources["vars.nat_gateway_enabled"])
+	assert.Equal(t, "prod.yaml", sources["vars.name"])
+	assert.Equal(t, "prod.yaml", sources["vars.stage"])
+	assert.Equal(t, []string{"_defaults.yaml", "catalog/vpc.yaml", "prod.yaml"}, metadata["files"])
+
+	// The metadata is not added when the source tracking is disabled
+	_, mapResult, err = ProcessYAMLConfigFiles(basePath, []string{filepath.Join(basePath, "prod.yaml")}, false, false)
+	assert.Nil(t, err)
+	components = mapResult["prod"].(map[interface{}]interface{})["components"].(map[string]interface{})
+	vpcComponent = components["terraform"].(map[string]interface{})["vpc"].(map[string]interface{})
+	assert.NotContains(t, vpcComponent, "_metadata")
+}
+
+func TestStackProcessorListMergeStrategy(t *testing.T) {
+	basePath := t.TempDir()
+
+	stack := `
+vars:
+  zones:
+    - a
+components:
+  terraform:
+    vpc:
+      vars:
+        zones:
+          - b
+`
+	err := os.WriteFile(filepath.Join(basePath, "dev.yaml"), []byte(stack), 0644)
+	assert.Nil(t, err)
+
+	getZones := func(mapResult map[string]interface{}) interface{} {
+		components := mapResult["dev"].(map[interface{}]interface{})["components"].(map[string]interface{})
+		vpcComponent := components["terraform"].(map[string]interface{})["vpc"].(map[string]interface{})
+		return vpcComponent["vars"].(map[interface{}]interface{})["zones"]
+	}
+
+	// The lists are replaced by default
+	_, mapResult, err := ProcessYAMLConfigFiles(basePath, []string{filepath.Join(basePath, "dev.yaml")}, false, false)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{"b"}, getZones(mapResult))
+
+	_, mapResult, err = ProcessYAMLConfigFilesWithOptions(basePath, []string{filepath.Join(basePath, "dev.yaml")}, false, false, ProcessOptions{ListMergeStrategy: "append"})
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{"a", "b"}, getZones(mapResult))
+}
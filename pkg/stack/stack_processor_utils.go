@@ -255,3 +255,75 @@ func GetGlobMatches(pattern string) ([]string, error) {
 
 	return fullMatches, nil
 }
+
+// recordConfigSources records the provided config file as the source of all the values in the config section.
+// The keys in the `sources` map are the dot-separated paths of the values (e.g. `components.terraform.vpc.vars.cidr`).
+// Maps are processed recursively, all other values (including lists) are recorded as a whole
+func recordConfigSources(config map[interface{}]interface{}, prefix string, source string, sources map[string]string) {
+	for k, v := range config {
+		key := fmt.Sprintf("%v", k)
+		if prefix == "" && key == "import" {
+			continue
+		}
+
+		valuePath := key
+		if prefix != "" {
+			valuePath = prefix + "." + key
+		}
+
+		if m, ok := v.(map[interface{}]interface{}); ok && len(m) > 0 {
+			recordConfigSources(m, valuePath, source, sources)
+		} else {
+			sources[valuePath] = source
+		}
+	}
+}
+
+// getComponentSourceMetadata returns the `_metadata` section of the component with the stack config file that defines each value
+// in the component's `vars`, `settings` and `env` sections, and the list of all the stack config files that contributed the values.
+// The sources are checked in the order used to deep-merge the component's sections: the component itself, the base components
+// (in the inheritance order), the component type section (e.g. `terraform.vars`), and the global section (e.g. `vars`)
+func getComponentSourceMetadata(
+	componentType string,
+	component string,
+	componentInheritanceChain []string,
+	componentSection map[string]interface{},
+	sources map[string]string) map[string]interface{} {
+
+	componentSources := map[string]string{}
+	var files []string
+
+	for _, section := range []string{"vars", "settings", "env"} {
+		sectionMap, ok := componentSection[section].(map[interface{}]interface{})
+		if !ok {
+			continue
+		}
+
+		valuePaths := map[string]string{}
+		recordConfigSources(sectionMap, section, "", valuePaths)
+
+		for valuePath := range valuePaths {
+			candidates := []string{fmt.Sprintf("components.%s.%s.%s", componentType, component, valuePath)}
+			for _, baseComponent := range componentInheritanceChain {
+				candidates = append(candidates, fmt.Sprintf("components.%s.%s.%s", componentType, baseComponent, valuePath))
+			}
+			candidates = append(candidates, fmt.Sprintf("%s.%s", componentType, valuePath), valuePath)
+
+			for _, candidate := range candidates {
+				if source, ok2 := sources[candidate]; ok2 {
+					componentSources[valuePath] = source
+					files = append(files, source)
+					break
+				}
+			}
+		}
+	}
+
+	files = utils.UniqueStrings(files)
+	sort.Strings(files)
+
+	return map[string]interface{}{
+		"sources": componentSources,
+		"files":   files,
+	}
+}
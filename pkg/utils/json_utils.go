@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 	jsoniter "github.com/json-iterator/go"
+	"io"
 	"io/ioutil"
 	"os"
 	"strings"
@@ -19,6 +20,17 @@ func PrintAsJSON(data interface{}) error {
 	return nil
 }
 
+// FprintAsJSON writes the provided value as JSON document to the provided writer
+func FprintAsJSON(w io.Writer, data interface{}) error {
+	var json = jsoniter.ConfigDefault
+	j, err := json.MarshalIndent(data, "", strings.Repeat(" ", 2))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(j))
+	return err
+}
+
 // WriteToFileAsJSON converts the provided value to YAML and writes it to the provided file
 func WriteToFileAsJSON(filePath string, data interface{}, fileMode os.FileMode) error {
 	var json = jsoniter.ConfigDefault
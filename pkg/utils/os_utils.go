@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	l "github.com/cloudposse/atmos/pkg/logger"
 	"github.com/fatih/color"
 	"os"
 )
@@ -10,6 +11,9 @@ import (
 func PrintErrorToStdErrorAndExit(err error) {
 	if err != nil {
 		c := color.New(color.FgRed)
+		if !l.UseColors(color.Error) {
+			c.DisableColor()
+		}
 		_, err2 := c.Fprintln(color.Error, err.Error()+"\n")
 		if err2 != nil {
 			fmt.Println("Error sending the error message to std.Error:")
@@ -1,5 +1,10 @@
 package utils
 
+import "regexp"
+
+// terraformWorkspaceInvalidCharsRegexp matches the characters that are not allowed in Terraform workspace names
+var terraformWorkspaceInvalidCharsRegexp = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
 // UniqueStrings returns a unique subset of the string slice provided
 func UniqueStrings(input []string) []string {
 	u := make([]string, 0, len(input))
@@ -14,3 +19,42 @@ func UniqueStrings(input []string) []string {
 
 	return u
 }
+
+// LevenshteinDistance returns the minimum number of single-character edits (insertions, deletions or substitutions)
+// required to change one string into the other
+func LevenshteinDistance(a string, b string) int {
+	s1 := []rune(a)
+	s2 := []rune(b)
+
+	prev := make([]int, len(s2)+1)
+	curr := make([]int, len(s2)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(s1); i++ {
+		curr[0] = i
+		for j := 1; j <= len(s2); j++ {
+			cost := 1
+			if s1[i-1] == s2[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j] + 1
+			if curr[j-1]+1 < curr[j] {
+				curr[j] = curr[j-1] + 1
+			}
+			if prev[j-1]+cost < curr[j] {
+				curr[j] = prev[j-1] + cost
+			}
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(s2)]
+}
+
+// SanitizeTerraformWorkspaceName replaces the characters not allowed in Terraform workspace names (e.g. `/`) with `-`
+func SanitizeTerraformWorkspaceName(name string) string {
+	return terraformWorkspaceInvalidCharsRegexp.ReplaceAllString(name, "-")
+}
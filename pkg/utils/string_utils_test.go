@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLevenshteinDistance(t *testing.T) {
+	assert.Equal(t, 0, LevenshteinDistance("tenant1-ue2-dev", "tenant1-ue2-dev"))
+	assert.Equal(t, 1, LevenshteinDistance("tenant1-ue2-dev", "tenant1-ue2-dv"))
+	assert.Equal(t, 1, LevenshteinDistance("tenant1-ue2-dev", "tenant2-ue2-dev"))
+	assert.Equal(t, 4, LevenshteinDistance("tenant1-ue2-dev", "tenant1-ue2-prod"))
+	assert.Equal(t, 3, LevenshteinDistance("", "dev"))
+	assert.Equal(t, 3, LevenshteinDistance("dev", ""))
+}
+
+func TestSanitizeTerraformWorkspaceName(t *testing.T) {
+	assert.Equal(t, "tenant1-ue2-dev", SanitizeTerraformWorkspaceName("tenant1-ue2-dev"))
+	assert.Equal(t, "tenant1-ue2-dev-infra-vpc", SanitizeTerraformWorkspaceName("tenant1-ue2-dev-infra/vpc"))
+	assert.Equal(t, "prod_1.us-east-2", SanitizeTerraformWorkspaceName("prod_1.us-east-2"))
+	assert.Equal(t, "dev-vpc--test-", SanitizeTerraformWorkspaceName("dev vpc:{test}"))
+	assert.Equal(t, "", SanitizeTerraformWorkspaceName(""))
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 	"gopkg.in/yaml.v2"
+	"io"
 	"io/ioutil"
 	"os"
 )
@@ -17,6 +18,16 @@ func PrintAsYAML(data interface{}) error {
 	return nil
 }
 
+// FprintAsYAML writes the provided value as YAML document to the provided writer
+func FprintAsYAML(w io.Writer, data interface{}) error {
+	y, err := yaml.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(y))
+	return err
+}
+
 // WriteToFileAsYAML converts the provided value to YAML and writes it to the provided file
 func WriteToFileAsYAML(filePath string, data interface{}, fileMode os.FileMode) error {
 	y, err := yaml.Marshal(data)